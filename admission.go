@@ -0,0 +1,27 @@
+package cubby
+
+import "errors"
+
+// ErrCacheFull is returned by TrySet when a cache created WithRejectWhenFull
+// is full and the key is not already present, and by writes that report
+// their outcome when the cache has no room for an item.
+var ErrCacheFull = errors.New("cubby: cache is full")
+
+// TrySet is like Set but returns ErrCacheFull, without storing value, if the
+// cache was created WithRejectWhenFull and is full and key is not already
+// present, or if no room can be made for value, and ErrClosed if the cache is
+// closed. It returns nil once value is stored.
+func (c *Cache[K, V]) TrySet(key K, value V) error {
+	return c.storeItem(key, c.newItem(value))
+}
+
+// admits reports whether key may be stored: it is false only for a new key
+// of a full cache created WithRejectWhenFull. The caller must hold the write
+// lock.
+func (c *Cache[K, V]) admits(key K) bool {
+	if !c.rejectWhenFull || c.capacity <= 0 || len(c.items) < c.capacity {
+		return true
+	}
+	_, ok := c.items[key]
+	return ok
+}
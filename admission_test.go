@@ -0,0 +1,108 @@
+package cubby
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrySet(t *testing.T) {
+	var evicted []string
+	cache := NewCache(
+		WithCapacity[string, int](2),
+		WithRejectWhenFull[string, int](),
+		WithOnEvict(func(key string, _ int, _ EvictReason) {
+			evicted = append(evicted, key)
+		}),
+	)
+	cases := []struct {
+		key  string
+		want error
+	}{
+		{key: "a", want: nil},
+		{key: "b", want: nil},
+		{key: "c", want: ErrCacheFull},
+		{key: "a", want: nil}, // updates are admitted
+	}
+	for i, c := range cases {
+		if err := cache.TrySet(c.key, i); !errors.Is(err, c.want) {
+			t.Fatalf("%s:"+errorString, c.key, err, c.want)
+		}
+	}
+	cache.Set("d", 4) // dropped silently
+	if _, ok := cache.Get("d"); ok {
+		t.Fatalf("Got key d in a full cache but wanted it refused")
+	}
+	if v, _ := cache.Get("a"); v != 3 || cache.Len() != 2 {
+		t.Fatalf(errorString, cache.ToMap(), map[string]int{"a": 3, "b": 1})
+	}
+	if len(evicted) != 0 {
+		t.Fatalf(errorString, evicted, []string{})
+	}
+	if got := cache.Stats().Rejected; got != 2 {
+		t.Fatalf(errorString, got, 2)
+	}
+	cache.Delete("b")
+	if err := cache.TrySet("c", 5); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+}
+
+func TestTrySetUnbounded(t *testing.T) {
+	cache := NewCache(WithRejectWhenFull[string, int]())
+	for _, k := range keys {
+		if err := cache.TrySet(k, 1); err != nil {
+			t.Fatalf(errorString, err, nil)
+		}
+	}
+}
+
+func TestRejectedWritesReportFailure(t *testing.T) {
+	cases := map[string]func(c *Cache[string, int]) (any, any){
+		"Merge": func(c *Cache[string, int]) (any, any) {
+			src := NewCache[string, int]()
+			src.Set("a", 2) // replaces a
+			src.Set("y", 2) // refused
+			return c.Merge(src, MergeAll), 1
+		},
+		"LoadOrStore": func(c *Cache[string, int]) (any, any) {
+			v, loaded := c.LoadOrStore("y", 2)
+			return []any{v, loaded}, []any{0, false}
+		},
+		"LoadOrStore present": func(c *Cache[string, int]) (any, any) {
+			v, loaded := c.LoadOrStore("a", 2)
+			return []any{v, loaded}, []any{1, true}
+		},
+		"GetOrSetToExpire": func(c *Cache[string, int]) (any, any) {
+			v, loaded := c.GetOrSetToExpire("y", 2, time.Hour)
+			return []any{v, loaded}, []any{0, false}
+		},
+		"SetToExpireIfLonger": func(c *Cache[string, int]) (any, any) {
+			return c.SetToExpireIfLonger("y", 2, time.Hour), false
+		},
+		"SetItemChecked": func(c *Cache[string, int]) (any, any) {
+			return c.SetItemChecked("y", Item[int]{Value: 2}), ErrCacheFull
+		},
+		"GetOrCompute": func(c *Cache[string, int]) (any, any) {
+			v, err := c.GetOrCompute("y", func() (int, error) { return 2, nil })
+			return []any{v, err}, []any{2, ErrCacheFull}
+		},
+	}
+	for name, fn := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(
+				WithCapacity[string, int](2),
+				WithRejectWhenFull[string, int](),
+			)
+			cache.Set("a", 1)
+			cache.Set("b", 1)
+			if got, want := fn(cache); !reflect.DeepEqual(got, want) {
+				t.Fatalf(errorString, got, want)
+			}
+			if _, ok := cache.Get("y"); ok {
+				t.Fatalf("Got key y in a full cache but wanted it refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package cubby
+
+import "time"
+
+// Oldest returns the key and item of the unexpired item with the earliest
+// CreatedAt date, or false if there is none. It shows how stale the oldest
+// data in the cache is.
+func (c *Cache[K, V]) Oldest() (K, Item[V], bool) {
+	return c.extreme(func(a, b Item[V]) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
+
+// Newest returns the key and item of the unexpired item with the latest
+// CreatedAt date, or false if there is none.
+func (c *Cache[K, V]) Newest() (K, Item[V], bool) {
+	return c.extreme(func(a, b Item[V]) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+}
+
+// extreme returns the key and item of the unexpired item that is first
+// ordered by less.
+func (c *Cache[K, V]) extreme(less func(a, b Item[V]) bool) (K, Item[V], bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	var key K
+	var item Item[V]
+	found := false
+	for k, e := range c.items {
+		if e.item.expiredAt(now) {
+			continue
+		}
+		if !found || less(e.item, item) {
+			key, item, found = k, e.item, true
+		}
+	}
+	return key, item, found
+}
+
+// ItemsCreatedBetween returns a copy of the unexpired items whose CreatedAt
+// dates fall between start and end, inclusive, such as to see what was cached
+// in the last few minutes.
+func (c *Cache[K, V]) ItemsCreatedBetween(start, end time.Time) map[K]Item[V] {
+	return c.itemsWhere(func(item Item[V]) bool {
+		return within(item.CreatedAt, start, end)
+	})
+}
+
+// ItemsExpiringBetween returns a copy of the unexpired items whose ExpiredAt
+// dates fall between start and end, inclusive, such as to see what is about
+// to expire. Items that never expire are not included.
+func (c *Cache[K, V]) ItemsExpiringBetween(start, end time.Time) map[K]Item[V] {
+	return c.itemsWhere(func(item Item[V]) bool {
+		return !item.ExpiredAt.IsZero() && within(item.ExpiredAt, start, end)
+	})
+}
+
+// itemsWhere returns a copy of the unexpired items for which pred returns
+// true.
+func (c *Cache[K, V]) itemsWhere(pred func(Item[V]) bool) map[K]Item[V] {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	items := make(map[K]Item[V])
+	for k, e := range c.items {
+		if !e.item.expiredAt(now) && pred(e.item) {
+			items[k] = e.item
+		}
+	}
+	return items
+}
+
+// within returns true if t is between start and end, inclusive.
+func within(t, start, end time.Time) bool {
+	return !t.Before(start) && !t.After(end)
+}
@@ -0,0 +1,93 @@
+package cubby
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestOldestAndNewest(t *testing.T) {
+	cases := map[string]struct {
+		items  map[string]Item[int]
+		oldest string
+		newest string
+		ok     bool
+	}{
+		"empty": {items: map[string]Item[int]{}},
+		"one": {
+			items:  map[string]Item[int]{"x": {Value: 1, CreatedAt: now}},
+			oldest: "x", newest: "x", ok: true,
+		},
+		"many": {
+			items: map[string]Item[int]{
+				"x": {Value: 1, CreatedAt: past},
+				"y": {Value: 2, CreatedAt: now},
+				"z": {Value: 3, CreatedAt: now.Add(1 * time.Minute)},
+			},
+			oldest: "x", newest: "z", ok: true,
+		},
+		"skips expired": {
+			items: map[string]Item[int]{
+				"x": {Value: 1, CreatedAt: past.Add(-1 * time.Hour), ExpiredAt: past},
+				"y": {Value: 2, CreatedAt: past},
+				"z": {Value: 3, CreatedAt: now, ExpiredAt: future},
+			},
+			oldest: "y", newest: "z", ok: true,
+		},
+		"all expired": {
+			items: map[string]Item[int]{"x": {Value: 1, CreatedAt: past, ExpiredAt: past}},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			for k, item := range c.items {
+				cache.SetItem(k, item)
+			}
+			k, item, ok := cache.Oldest()
+			if k != c.oldest || ok != c.ok || (ok && item != c.items[k]) {
+				t.Fatalf(errorString, k, c.oldest)
+			}
+			k, item, ok = cache.Newest()
+			if k != c.newest || ok != c.ok || (ok && item != c.items[k]) {
+				t.Fatalf(errorString, k, c.newest)
+			}
+		})
+	}
+}
+
+func TestItemsBetween(t *testing.T) {
+	cache := NewCache(WithClock[string, int](cubbytest.NewFakeClock(now)))
+	cache.SetItem("old", Item[int]{Value: 1, CreatedAt: now.Add(-time.Hour), ExpiredAt: now.Add(time.Minute)})
+	cache.SetItem("new", Item[int]{Value: 2, CreatedAt: now.Add(-time.Minute), ExpiredAt: now.Add(time.Hour)})
+	cache.SetItem("forever", Item[int]{Value: 3, CreatedAt: now})
+	cache.SetItem("expired", Item[int]{Value: 4, CreatedAt: now.Add(-time.Minute), ExpiredAt: now})
+	cases := map[string]struct {
+		got  map[string]Item[int]
+		want []string
+	}{
+		"created in last 5 minutes": {
+			got:  cache.ItemsCreatedBetween(now.Add(-5*time.Minute), now),
+			want: []string{"forever", "new"},
+		},
+		"created at bounds": {
+			got:  cache.ItemsCreatedBetween(now.Add(-time.Hour), now.Add(-time.Minute)),
+			want: []string{"new", "old"},
+		},
+		"expiring in next 5 minutes": {
+			got:  cache.ItemsExpiringBetween(now, now.Add(5*time.Minute)),
+			want: []string{"old"},
+		},
+		"expiring ever": {
+			got:  cache.ItemsExpiringBetween(now, future.Add(time.Hour)),
+			want: []string{"new", "old"},
+		},
+	}
+	for name, c := range cases {
+		if got := sortedKeys(c.got); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s:"+errorString, name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cubby
+
+// BatchRead calls fn while holding the read lock once, so fn can retrieve
+// many items with get without locking for each one. get returns items as
+// GetItem does by default, expired or not, and records the access. Since it
+// holds only the read lock, it neither extends the expiration dates of a
+// cache created WithSlidingTTL nor removes the expired items of a cache
+// created WithLazyExpiration. get is only valid until fn returns; it must not
+// be retained or called from another goroutine. fn must not call other
+// methods of the cache that write, or it will deadlock.
+func (c *Cache[K, V]) BatchRead(fn func(get func(K) (Item[V], bool))) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	fn(c.read)
+}
+
+// GetOrdered retrieves the item values mapped to keys under a single read
+// lock. The results line up with keys: values[i] is the value of keys[i] and
+// found[i] reports whether it was present, with values[i] the zero value if
+// not. Expired items are treated as GetItem treats them, except that a cache
+// created WithLazyExpiration reports them missing without removing them.
+func (c *Cache[K, V]) GetOrdered(keys []K) (values []V, found []bool) {
+	values = make([]V, len(keys))
+	found = make([]bool, len(keys))
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	for i, key := range keys {
+		item, ok := c.read(key)
+		if ok && c.lazyExpiration && item.expiredAt(now) {
+			continue
+		}
+		values[i], found[i] = item.Value, ok
+	}
+	return values, found
+}
+
+// SetMany adds or updates the item values mapped to the keys of items, as Set
+// would, under a single write lock.
+func (c *Cache[K, V]) SetMany(items map[K]V) {
+	c.SetManyFunc(items, func(_, incoming V) V {
+		return incoming
+	})
+}
+
+// SetManyFunc is like SetMany but, for keys already mapped to an item that
+// is not expired, stores the value merge returns given the existing and the
+// incoming value. It suits accumulating values during bulk ingestion, such as
+// summing counters, without reading and writing each key separately. merge
+// is called with the write lock held and must not call methods of the cache.
+func (c *Cache[K, V]) SetManyFunc(items map[K]V, merge func(existing, incoming V) V) {
+	var evicted []eviction[K, V]
+	c.lock()
+	now := c.clock.Now()
+	for k, v := range items {
+		if e, ok := c.items[k]; ok && !e.item.expiredAt(now) {
+			v = merge(e.item.Value, v)
+		}
+		ev, _ := c.set(k, c.newItem(v), 1)
+		evicted = append(evicted, ev...)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+}
@@ -0,0 +1,178 @@
+package cubby
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestBatchRead(t *testing.T) {
+	cache := NewCache[string, int]()
+	values := []int{1, 2, 3}
+	for i, k := range keys {
+		cache.Set(k, values[i])
+	}
+	sum := 0
+	cache.BatchRead(func(get func(string) (Item[int], bool)) {
+		for _, k := range append(keys, "w") {
+			if item, ok := get(k); ok {
+				sum += item.Value
+			}
+		}
+	})
+	if sum != 6 {
+		t.Fatalf(errorString, sum, 6)
+	}
+}
+
+func TestBatchReadSliding(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithSlidingTTL[string, int](1*time.Minute, 0),
+		WithClock[string, int](clock),
+	)
+	cache.Set("x", 1)
+	cache.SetItem("z", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+	clock.Advance(30 * time.Second)
+	cache.BatchRead(func(get func(string) (Item[int], bool)) {
+		if item, _ := get("x"); !item.ExpiredAt.Equal(now.Add(1 * time.Minute)) {
+			t.Fatalf(errorString, item.ExpiredAt, now.Add(1*time.Minute))
+		}
+		if item, ok := get("z"); !ok || item.Value != 2 {
+			t.Fatalf(errorString, item.Value, 2)
+		}
+	})
+	if got, _ := cache.LastAccessed("x"); !got.Equal(clock.Now()) {
+		t.Fatalf(errorString, got, clock.Now())
+	}
+}
+
+func TestGetOrdered(t *testing.T) {
+	cases := map[string]struct {
+		opts   []Option[string, int]
+		keys   []string
+		values []int
+		found  []bool
+	}{
+		"in order":  {keys: []string{"x", "y"}, values: []int{1, 2}, found: []bool{true, true}},
+		"reordered": {keys: []string{"y", "w", "x", "y"}, values: []int{2, 0, 1, 2}, found: []bool{true, false, true, true}},
+		"expired":   {keys: []string{"z", "x"}, values: []int{3, 1}, found: []bool{true, true}},
+		"lazy expired": {
+			opts: []Option[string, int]{WithLazyExpiration[string, int]()},
+			keys: []string{"z", "x"}, values: []int{0, 1}, found: []bool{false, true},
+		},
+		"empty": {keys: []string{}, values: []int{}, found: []bool{}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(c.opts...)
+			cache.Set("x", 1)
+			cache.Set("y", 2)
+			cache.SetItem("z", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+			values, found := cache.GetOrdered(c.keys)
+			if !reflect.DeepEqual(values, c.values) || !reflect.DeepEqual(found, c.found) {
+				t.Fatalf(errorString, []any{values, found}, []any{c.values, c.found})
+			}
+		})
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("a", 1)
+	cache.SetMany(map[string]int{"a": 10, "b": 20})
+	if got := cache.ToMap(); len(got) != 2 || got["a"] != 10 || got["b"] != 20 {
+		t.Fatalf(errorString, got, map[string]int{"a": 10, "b": 20})
+	}
+}
+
+func TestSetManyFunc(t *testing.T) {
+	sum := func(existing, incoming int) int { return existing + incoming }
+	cases := map[string]struct {
+		existing Item[int]
+		want     int
+	}{
+		"present": {existing: Item[int]{Value: 1, CreatedAt: now}, want: 11},
+		"expired": {existing: Item[int]{Value: 1, CreatedAt: past, ExpiredAt: past}, want: 10},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			cache.SetItem("a", c.existing)
+			cache.SetManyFunc(map[string]int{"a": 10, "b": 20}, sum)
+			if got := cache.GetOrZero("a"); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if got := cache.GetOrZero("b"); got != 20 {
+				t.Fatalf(errorString, got, 20)
+			}
+		})
+	}
+}
+
+func TestSetManyFuncEvicts(t *testing.T) {
+	var evicted int
+	cache := NewCache(
+		WithCapacity[string, int](2),
+		WithOnEvict(func(string, int, EvictReason) { evicted++ }),
+	)
+	cache.SetManyFunc(map[string]int{"a": 1, "b": 2, "c": 3}, func(_, v int) int { return v })
+	if cache.Len() != 2 || evicted != 1 {
+		t.Fatalf(errorString, []int{cache.Len(), evicted}, []int{2, 1})
+	}
+}
+
+func benchmarkKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkGetItem(b *testing.B) {
+	cache := NewCache[string, int]()
+	keys := benchmarkKeys(100)
+	for i, k := range keys {
+		cache.Set(k, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, k := range keys {
+			cache.GetItem(k)
+		}
+	}
+}
+
+func BenchmarkBatchRead(b *testing.B) {
+	cache := NewCache[string, int]()
+	keys := benchmarkKeys(100)
+	for i, k := range keys {
+		cache.Set(k, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.BatchRead(func(get func(string) (Item[int], bool)) {
+			for _, k := range keys {
+				get(k)
+			}
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	cache := NewCache[string, int]()
+	keys := benchmarkKeys(100)
+	for i, k := range keys {
+		cache.Set(k, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, k := range keys {
+			cache.Get(k)
+		}
+	}
+}
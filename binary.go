@@ -0,0 +1,230 @@
+package cubby
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// binaryMagic begins every binary snapshot.
+const binaryMagic = "cubbybin"
+
+// binaryVersion is the version of the binary snapshot format written by
+// SaveBinary. It is the byte that follows binaryMagic.
+const binaryVersion byte = 1
+
+// SaveBinary writes a compact binary snapshot of the items in the cache to w.
+// It is smaller and faster to load than the snapshot written by Save, but
+// requires K and V to be strings or to implement encoding.BinaryMarshaler,
+// unless the cache was created WithKeyCodec, which then encodes K.
+//
+// After a header, the snapshot holds the number of items followed by each
+// item's length-prefixed key and value, its CreatedAt and ExpiredAt dates as
+// Unix nanoseconds, and its flags. Dates are restored in UTC.
+func (c *Cache[K, V]) SaveBinary(w io.Writer) error {
+	items := c.Items()
+	var buf bytes.Buffer
+	buf.WriteString(binaryMagic)
+	buf.WriteByte(binaryVersion)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(items))))
+	var scratch []byte
+	for k, item := range items {
+		kb, err := c.encodeKey(k)
+		if err != nil {
+			return err
+		}
+		vb, err := marshalBinary(item.Value)
+		if err != nil {
+			return err
+		}
+		scratch = binary.AppendUvarint(scratch[:0], uint64(len(kb)))
+		scratch = append(scratch, kb...)
+		scratch = binary.AppendUvarint(scratch, uint64(len(vb)))
+		scratch = append(scratch, vb...)
+		scratch = binary.AppendVarint(scratch, unixNano(item.CreatedAt))
+		scratch = binary.AppendVarint(scratch, unixNano(item.ExpiredAt))
+		var flags byte
+		if item.Pinned {
+			flags |= 1
+		}
+		scratch = append(scratch, flags)
+		buf.Write(scratch)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// LoadBinary reads a snapshot written by SaveBinary from r and adds its items
+// to the cache, replacing the items of any keys already present. K and V must
+// be strings or their pointers must implement encoding.BinaryUnmarshaler.
+// Errors reading from r are returned as is; errors decoding the snapshot wrap
+// ErrCorruptSnapshot. If the cache is closed, nothing is added and it returns
+// ErrClosed.
+func (c *Cache[K, V]) LoadBinary(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return fmt.Errorf("%w: missing header", ErrCorruptSnapshot)
+	}
+	d := &binaryDecoder{data: data[len(binaryMagic):]}
+	if v := d.byte(); d.err == nil && v != binaryVersion {
+		return fmt.Errorf("%w: %w %d", ErrCorruptSnapshot, ErrUnsupportedVersion, v)
+	}
+	n := d.uvarint()
+	if n > uint64(len(d.data)) { // each item takes at least one byte
+		return fmt.Errorf("%w: implausible item count %d", ErrCorruptSnapshot, n)
+	}
+	items := make(map[K]Item[V], n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		kb, vb := d.bytes(), d.bytes()
+		created, expired, flags := d.varint(), d.varint(), d.byte()
+		if d.err != nil {
+			break
+		}
+		k, err := c.decodeKey(kb)
+		if err != nil {
+			return err
+		}
+		v, err := unmarshalBinary[V](vb)
+		if err != nil {
+			return err
+		}
+		items[k] = Item[V]{
+			Value:     v,
+			CreatedAt: fromUnixNano(created),
+			ExpiredAt: fromUnixNano(expired),
+			Pinned:    flags&1 != 0,
+		}
+	}
+	if d.err != nil {
+		return fmt.Errorf("%w: %v", ErrCorruptSnapshot, d.err)
+	}
+	return c.insertLoaded(items)
+}
+
+// binaryDecoder reads the fields of a binary snapshot. After the first error,
+// reads return zero values and err is set.
+type binaryDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *binaryDecoder) fail(what string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("truncated or malformed %s", what)
+	}
+	d.data = nil
+}
+
+func (d *binaryDecoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail("byte")
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail("length")
+		return 0
+	}
+	d.data = d.data[n:]
+	return x
+}
+
+func (d *binaryDecoder) varint() int64 {
+	x, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail("date")
+		return 0
+	}
+	d.data = d.data[n:]
+	return x
+}
+
+func (d *binaryDecoder) bytes() []byte {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail("field")
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// encodeKey encodes k with the cache's key codec if it has one, or else with
+// marshalBinary.
+func (c *Cache[K, V]) encodeKey(k K) ([]byte, error) {
+	if c.keyCodec == nil {
+		return marshalBinary(k)
+	}
+	s, err := c.keyCodec.encode(k)
+	return []byte(s), err
+}
+
+// decodeKey undoes encodeKey.
+func (c *Cache[K, V]) decodeKey(data []byte) (K, error) {
+	if c.keyCodec == nil {
+		return unmarshalBinary[K](data)
+	}
+	k, err := c.keyCodec.decode(string(data))
+	if err != nil {
+		return k, fmt.Errorf("%w: key %q: %v", ErrCorruptSnapshot, data, err)
+	}
+	return k, nil
+}
+
+// marshalBinary encodes v, which must be a string or implement
+// encoding.BinaryMarshaler.
+func marshalBinary[T any](v T) ([]byte, error) {
+	switch x := any(v).(type) {
+	case string:
+		return []byte(x), nil
+	case encoding.BinaryMarshaler:
+		return x.MarshalBinary()
+	}
+	return nil, fmt.Errorf("cubby: %T does not implement encoding.BinaryMarshaler", v)
+}
+
+// unmarshalBinary decodes data into a T, which must be a string or whose
+// pointer must implement encoding.BinaryUnmarshaler.
+func unmarshalBinary[T any](data []byte) (T, error) {
+	var v T
+	switch p := any(&v).(type) {
+	case *string:
+		*p = string(data)
+	case encoding.BinaryUnmarshaler:
+		if err := p.UnmarshalBinary(data); err != nil {
+			return v, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+		}
+	default:
+		return v, fmt.Errorf("cubby: %T does not implement encoding.BinaryUnmarshaler", p)
+	}
+	return v, nil
+}
+
+// unixNano returns t as Unix nanoseconds, or 0 if t is the zero time.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano undoes unixNano, returning UTC times.
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
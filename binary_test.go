@@ -0,0 +1,146 @@
+package cubby
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// point is a value type that supplies its own binary encoding.
+type point struct {
+	X, Y int32
+}
+
+func (p point) MarshalBinary() ([]byte, error) {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(p.X))
+	return binary.LittleEndian.AppendUint32(b, uint32(p.Y)), nil
+}
+
+func (p *point) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errors.New("point: wrong length")
+	}
+	p.X = int32(binary.LittleEndian.Uint32(data))
+	p.Y = int32(binary.LittleEndian.Uint32(data[4:]))
+	return nil
+}
+
+func TestSaveAndLoadBinary(t *testing.T) {
+	created := time.Date(2023, 12, 1, 8, 30, 0, 0, time.UTC)
+	items := map[string]Item[point]{
+		"x": {Value: point{1, -2}, CreatedAt: created},
+		"y": {Value: point{3, 4}, CreatedAt: created, ExpiredAt: created.Add(1 * time.Hour)},
+		"z": {Value: point{5, 6}, CreatedAt: created, Pinned: true},
+	}
+	cache := NewCache[string, point]()
+	for k, item := range items {
+		cache.SetItem(k, item)
+	}
+	var buf bytes.Buffer
+	if err := cache.SaveBinary(&buf); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	loaded := NewCache[string, point]()
+	if err := loaded.LoadBinary(&buf); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if loaded.Len() != len(items) {
+		t.Fatalf(errorString, loaded.Len(), len(items))
+	}
+	for k, want := range items {
+		if got, _ := loaded.GetItem(k); got != want {
+			t.Fatalf(errorString, got, want)
+		}
+	}
+}
+
+func TestLoadBinaryErrors(t *testing.T) {
+	cache := NewCache[string, point]()
+	cache.Set("x", point{1, 2})
+	var buf bytes.Buffer
+	cache.SaveBinary(&buf)
+	valid := buf.Bytes()
+	cases := map[string]struct {
+		data    []byte
+		version bool
+	}{
+		"empty":          {data: nil},
+		"json snapshot":  {data: []byte(snapshotMagic + "\x01{}")},
+		"future version": {data: []byte(binaryMagic + "\x02\x00"), version: true},
+		"truncated":      {data: valid[:len(valid)-3]},
+		"huge count":     {data: []byte(binaryMagic + "\x01\xff\xff\x03")},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewCache[string, point]().LoadBinary(bytes.NewReader(c.data))
+			if !errors.Is(err, ErrCorruptSnapshot) {
+				t.Fatalf(errorString, err, ErrCorruptSnapshot)
+			}
+			if got := errors.Is(err, ErrUnsupportedVersion); got != c.version {
+				t.Fatalf(errorString, got, c.version)
+			}
+		})
+	}
+}
+
+func TestSaveBinaryUnsupportedType(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	var buf bytes.Buffer
+	if err := cache.SaveBinary(&buf); err == nil {
+		t.Fatalf("Wanted an error saving int values but got none")
+	}
+}
+
+func benchmarkSnapshotCache(n int) *Cache[string, point] {
+	cache := NewCache[string, point]()
+	for i := 0; i < n; i++ {
+		cache.SetToExpire(strconv.Itoa(i), point{int32(i), int32(-i)}, 1*time.Hour)
+	}
+	return cache
+}
+
+func BenchmarkSave(b *testing.B) {
+	cache := benchmarkSnapshotCache(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		cache.Save(&buf)
+		b.SetBytes(int64(buf.Len()))
+	}
+}
+
+func BenchmarkSaveBinary(b *testing.B) {
+	cache := benchmarkSnapshotCache(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		cache.SaveBinary(&buf)
+		b.SetBytes(int64(buf.Len()))
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	var buf bytes.Buffer
+	benchmarkSnapshotCache(100000).Save(&buf)
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewCache[string, point]().Load(bytes.NewReader(data))
+	}
+}
+
+func BenchmarkLoadBinary(b *testing.B) {
+	var buf bytes.Buffer
+	benchmarkSnapshotCache(100000).SaveBinary(&buf)
+	data := buf.Bytes()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewCache[string, point]().LoadBinary(bytes.NewReader(data))
+	}
+}
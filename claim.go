@@ -0,0 +1,61 @@
+package cubby
+
+import "time"
+
+// The metadata keys under which Claim records a claim.
+const (
+	claimOwnerKey = "cubby.claim.owner"
+	claimUntilKey = "cubby.claim.until"
+)
+
+// Claim marks the unexpired item mapped to key as owned by owner for lease,
+// so that cooperating workers can take items to process one at a time. It
+// returns false, leaving the item as is, if key is missing or expired,
+// another owner's lease on it has not run out, or the cache is closed.
+// Claiming an item already owned by owner renews the lease. The claim is kept
+// in the item's metadata, as returned by GetMeta, so it is dropped when key is
+// set again.
+func (c *Cache[K, V]) Claim(key K, owner string, lease time.Duration) bool {
+	c.lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	now := c.clock.Now()
+	e, ok := c.items[key]
+	if !ok || e.item.expiredAt(now) {
+		return false
+	}
+	if o, ok := e.meta[claimOwnerKey].(string); ok && o != owner {
+		if until, _ := e.meta[claimUntilKey].(time.Time); now.Before(until) {
+			return false
+		}
+	}
+	if e.meta == nil {
+		e.meta = make(map[string]any)
+	}
+	e.meta[claimOwnerKey] = owner
+	e.meta[claimUntilKey] = now.Add(lease)
+	return true
+}
+
+// Release ends owner's claim on the item mapped to key and reports whether
+// owner held it, even if its lease had run out. It returns false, leaving the
+// claim as is, if the cache is closed.
+func (c *Cache[K, V]) Release(key K, owner string) bool {
+	c.lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	if o, ok := e.meta[claimOwnerKey].(string); !ok || o != owner {
+		return false
+	}
+	delete(e.meta, claimOwnerKey)
+	delete(e.meta, claimUntilKey)
+	return true
+}
@@ -0,0 +1,47 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestClaim(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	cache.Set("job", 1)
+	cache.SetItem("done", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+	steps := []struct {
+		name string
+		do   func() bool
+		want bool
+	}{
+		{name: "claim", do: func() bool { return cache.Claim("job", "a", time.Minute) }, want: true},
+		{name: "claimed by other", do: func() bool { return cache.Claim("job", "b", time.Minute) }, want: false},
+		{name: "renew", do: func() bool { return cache.Claim("job", "a", time.Minute) }, want: true},
+		{name: "release by other", do: func() bool { return cache.Release("job", "b") }, want: false},
+		{name: "lease runs out", do: func() bool {
+			clock.Advance(time.Minute)
+			return cache.Claim("job", "b", time.Minute)
+		}, want: true},
+		{name: "release by former owner", do: func() bool { return cache.Release("job", "a") }, want: false},
+		{name: "release", do: func() bool { return cache.Release("job", "b") }, want: true},
+		{name: "claim released", do: func() bool { return cache.Claim("job", "a", time.Minute) }, want: true},
+		{name: "set drops claim", do: func() bool {
+			cache.Set("job", 3)
+			return cache.Claim("job", "b", time.Minute)
+		}, want: true},
+		{name: "missing", do: func() bool { return cache.Claim("missing", "a", time.Minute) }, want: false},
+		{name: "expired", do: func() bool { return cache.Claim("done", "a", time.Minute) }, want: false},
+		{name: "release missing", do: func() bool { return cache.Release("missing", "a") }, want: false},
+	}
+	for _, s := range steps {
+		if got := s.do(); got != s.want {
+			t.Fatalf("%s:"+errorString, s.name, got, s.want)
+		}
+	}
+	if _, meta, _ := cache.GetMeta("job"); meta[claimOwnerKey] != "b" {
+		t.Fatalf(errorString, meta[claimOwnerKey], "b")
+	}
+}
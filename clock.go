@@ -0,0 +1,33 @@
+package cubby
+
+import "time"
+
+// Clock reports the current time. A Cache uses its Clock to timestamp items
+// and to decide whether they have expired.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts an ordinary function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// utcClock is the default Clock. It reports time now in UTC.
+type utcClock struct{}
+
+// Now returns time now in UTC.
+func (utcClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// localClock reports time now in the local time zone.
+type localClock struct{}
+
+// Now returns time now in local time.
+func (localClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,39 @@
+package cubby
+
+import "errors"
+
+// ErrClosed is returned by the writes that return errors, such as TrySet,
+// once a cache is closed.
+var ErrClosed = errors.New("cubby: cache is closed")
+
+// Close marks the cache as closed, after storing any values pending from
+// SetDebounced, so that writes made after it are caught rather than silently
+// applied to a cache that is no longer maintained. Once the cache is closed,
+// writes that return errors, such as TrySet, Load, and GetOrCompute for a
+// missing key, return ErrClosed; writes that report success, such as Pin,
+// Claim, Release, and Move into the cache, return false; and all other
+// writes, such as Set, are no-ops. None of them change the cache. Reads,
+// deletes, and sweeps still work, so the remaining items can be drained.
+// Closing a closed cache does nothing. It always returns nil, so that a Cache
+// is an io.Closer.
+func (c *Cache[K, V]) Close() error {
+	c.Flush()
+	c.lock()
+	c.closed = true
+	c.mu.Unlock()
+	return nil
+}
+
+// IsClosed reports whether Close has been called.
+func (c *Cache[K, V]) IsClosed() bool {
+	c.rlock()
+	defer c.mu.RUnlock()
+	return c.closed
+}
+
+// Close stops ticking, ending the cache's background jobs as Stop does, and
+// then closes the cache. It always returns nil.
+func (tc *TickingCache[K, V]) Close() error {
+	tc.Stop()
+	return tc.Cache.Close()
+}
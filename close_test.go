@@ -0,0 +1,150 @@
+package cubby
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	_ io.Closer = (*Cache[string, int])(nil)
+	_ io.Closer = (*TickingCache[string, int])(nil)
+)
+
+func TestClose(t *testing.T) {
+	cache := NewCache(WithTier[string, int]("short", time.Minute))
+	cache.Set("x", 1)
+	cache.SetDebounced("pending", 2, time.Hour)
+	if err := cache.Close(); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if !cache.IsClosed() {
+		t.Fatalf("Got an open cache after Close but wanted it closed")
+	}
+	errs := map[string]error{
+		"TrySet":         cache.TrySet("y", 1),
+		"SetItemChecked": cache.SetItemChecked("y", Item[int]{Value: 1, CreatedAt: now}),
+		"SetInTier":      cache.SetInTier("y", 1, "short"),
+	}
+	for name, err := range errs {
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("%s:"+errorString, name, err, ErrClosed)
+		}
+	}
+	cache.Set("y", 1)
+	cache.Set("x", 10)
+	Increment(cache, "x", 1)
+	if got, want := cache.ToMap(), map[string]int{"x": 1, "pending": 2}; len(got) != 2 || got["x"] != 1 || got["pending"] != 2 {
+		t.Fatalf(errorString, got, want)
+	}
+	cache.Delete("x")
+	if cache.Len() != 1 {
+		t.Fatalf(errorString, cache.Len(), 1)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+}
+
+func TestClosedWritesReportFailure(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewCache[string, int]().Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+	cases := map[string]func(c *Cache[string, int]) (got, want any){
+		"SwapAll": func(c *Cache[string, int]) (any, any) {
+			return c.SwapAll(map[string]Item[int]{"y": {Value: 2}}) == nil, true
+		},
+		"Move": func(c *Cache[string, int]) (any, any) {
+			from := NewCache[string, int]()
+			from.Set("y", 2)
+			return []bool{Move(from, c, "y"), from.Len() == 1}, []bool{false, true}
+		},
+		"LoadOrStore": func(c *Cache[string, int]) (any, any) {
+			v, loaded := c.LoadOrStore("y", 2)
+			return []any{v, loaded}, []any{0, false}
+		},
+		"SetToExpireIfLonger": func(c *Cache[string, int]) (any, any) {
+			return c.SetToExpireIfLonger("x", 2, time.Hour), false
+		},
+		"GetOrCompute": func(c *Cache[string, int]) (any, any) {
+			_, err := c.GetOrCompute("y", func() (int, error) { return 2, nil })
+			return err, ErrClosed
+		},
+		"GetOrComputeMany": func(c *Cache[string, int]) (any, any) {
+			_, err := c.GetOrComputeMany([]string{"x", "y"}, func([]string) (map[string]int, error) {
+				return map[string]int{"y": 2}, nil
+			})
+			return err, ErrClosed
+		},
+		"UpdateAll": func(c *Cache[string, int]) (any, any) {
+			return c.UpdateAll(func(string, int) (int, bool) { return 2, true }), 0
+		},
+		"GetAndTouch": func(c *Cache[string, int]) (any, any) {
+			_, ok := c.GetAndTouch("x", time.Hour)
+			return ok, false
+		},
+		"GetAndReset": func(c *Cache[string, int]) (any, any) {
+			_, ok := GetAndReset(c, "x")
+			return ok, false
+		},
+		"Pin": func(c *Cache[string, int]) (any, any) {
+			return c.Pin("x"), false
+		},
+		"Claim": func(c *Cache[string, int]) (any, any) {
+			return c.Claim("x", "worker", time.Hour), false
+		},
+		"Merge": func(c *Cache[string, int]) (any, any) {
+			src := NewCache[string, int]()
+			src.Set("y", 2)
+			return c.Merge(src, MergeAll), 0
+		},
+		"Load": func(c *Cache[string, int]) (any, any) {
+			return c.Load(bytes.NewReader(snapshot)), ErrClosed
+		},
+		"SetWithMeta": func(c *Cache[string, int]) (any, any) {
+			c.SetWithMeta("x", 2, map[string]any{"a": 1})
+			_, meta, _ := c.GetMeta("x")
+			return meta == nil, true
+		},
+	}
+	for name, fn := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			cache.SetToExpire("x", 1, time.Minute)
+			before := cache.Items()
+			cache.Close()
+			if got, want := fn(cache); !reflect.DeepEqual(got, want) {
+				t.Fatalf(errorString, got, want)
+			}
+			if got := cache.Items(); !reflect.DeepEqual(got, before) {
+				t.Fatalf(errorString, got, before)
+			}
+		})
+	}
+}
+
+func TestTickingCacheClose(t *testing.T) {
+	cache := NewTickingCache[string, int](1 * time.Hour)
+	cache.Close()
+	if cache.IsRunning() || !cache.IsClosed() {
+		t.Fatalf(errorString, []bool{cache.IsRunning(), cache.IsClosed()}, []bool{false, true})
+	}
+}
+
+func TestReleaseClosed(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.Claim("x", "worker", time.Hour)
+	cache.Close()
+	if cache.Release("x", "worker") {
+		t.Fatalf("Got true releasing a claim in a closed cache but wanted false")
+	}
+	if _, meta, _ := cache.GetMeta("x"); meta[claimOwnerKey] != "worker" {
+		t.Fatalf(errorString, meta[claimOwnerKey], "worker")
+	}
+}
@@ -0,0 +1,356 @@
+package cubby
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrLoadTimeout is returned by GetOrCompute when waiting on another
+// goroutine's load of the same key takes longer than the cache's maximum
+// load wait.
+var ErrLoadTimeout = errors.New("cubby: timed out waiting for load")
+
+// ErrNotLoaded is returned by GetOrCompute when it waits on a load of its key
+// made by GetOrComputeMany whose loader returned no value for the key.
+var ErrNotLoaded = errors.New("cubby: loader returned no value for key")
+
+// errLoaderPanicked is returned to goroutines waiting on a load whose loader
+// panicked.
+var errLoaderPanicked = errors.New("cubby: loader panicked")
+
+// inflight tracks the outcomes of GetOrCompute loads by key.
+type inflight[K comparable, V any] struct {
+	mu sync.Mutex
+
+	// failed maps keys whose loads failed to their errors while they are
+	// cached by WithNegativeCaching.
+	failed map[K]failure
+}
+
+// failure is a cached load error.
+type failure struct {
+	err   error
+	until time.Time
+}
+
+// GetOrCompute retrieves the item value mapped to key from the cache. If key
+// is missing or expired, it calls loader, stores the returned value with Set,
+// and returns it. If loader returns an error, nothing is stored and the error
+// is returned. If the cache has no room for the value, it is returned with
+// ErrCacheFull. If the cache is closed, a missing or expired key is not loaded
+// and ErrClosed is returned.
+//
+// Concurrent calls for the same key share a single call to loader. loader is
+// run without holding the cache's lock, so a slow load does not block access
+// to other keys. If the cache was created WithMaxLoaders, loads of different
+// keys also wait for one another beyond that limit. If the cache was created
+// WithMaxLoadWait, calls waiting on another's load give up after the maximum
+// wait. If the cache was created WithLoadRetry, a failing loader is retried
+// before its error is returned, and if it was created WithNegativeCaching,
+// that error is returned again without calling loader for a while.
+func (c *Cache[K, V]) GetOrCompute(key K, loader func() (V, error)) (V, error) {
+	item, err := c.GetOrComputeItem(key, func() (Item[V], error) {
+		v, err := loader()
+		if err != nil {
+			return Item[V]{}, err
+		}
+		return c.newItem(v), nil
+	})
+	return item.Value, err
+}
+
+// GetOrComputeItem is like GetOrCompute but loader returns a whole item,
+// which is stored as is with SetItem. This suits loaders that learn an
+// item's dates along with its value, such as an absolute expiration date from
+// upstream metadata.
+func (c *Cache[K, V]) GetOrComputeItem(key K, loader func() (Item[V], error)) (Item[V], error) {
+	return c.getOrComputeItem(key, loader, c.storeItem)
+}
+
+// getOrComputeItem is GetOrComputeItem with the items it loads stored by
+// store rather than SetItem. An error store returns is returned with the
+// item.
+func (c *Cache[K, V]) getOrComputeItem(key K, loader func() (Item[V], error), store func(K, Item[V]) error) (Item[V], error) {
+	if item, ok := c.getLiveItem(key); ok {
+		return item, nil
+	}
+	if c.IsClosed() {
+		return Item[V]{}, ErrClosed
+	}
+	c.loads.mu.Lock()
+	if f, ok := c.loads.failed[key]; ok {
+		if c.clock.Now().Before(f.until) {
+			c.loads.mu.Unlock()
+			return Item[V]{}, f.err
+		}
+		delete(c.loads.failed, key)
+	}
+	c.loads.mu.Unlock()
+	cl, leader := c.group.join(key, c)
+	if !leader {
+		return c.await(key, cl, loader, store)
+	}
+	defer func() {
+		if cl.err != nil && c.negativeTTL > 0 {
+			c.loads.mu.Lock()
+			if c.loads.failed == nil {
+				c.loads.failed = make(map[K]failure)
+			}
+			c.loads.failed[key] = failure{cl.err, c.clock.Now().Add(c.negativeTTL)}
+			c.loads.mu.Unlock()
+		}
+		c.group.finish(key, cl)
+	}()
+	// A load may have completed between the miss above and joining the group.
+	if item, ok := c.getLiveItem(key); ok {
+		cl.item = item
+		return item, nil
+	}
+	cl.err = errLoaderPanicked // replaced if loader returns
+	cl.item, cl.err = c.load(loader)
+	if cl.err != nil {
+		c.logLoadError(key, cl.err)
+		return cl.item, cl.err
+	}
+	return cl.item, store(key, cl.item)
+}
+
+// await waits for the in-flight load cl of key and returns its result. If the
+// wait exceeds the cache's maximum load wait, it returns ErrLoadTimeout or, if
+// the cache falls back to loading, loads key itself. Items it stores are
+// stored by store.
+func (c *Cache[K, V]) await(key K, cl *call[V], loader func() (Item[V], error), store func(K, Item[V]) error) (Item[V], error) {
+	select {
+	case <-cl.done:
+		return c.adopt(key, cl, store)
+	default:
+	}
+	start := time.Now()
+	if c.maxLoadWait <= 0 {
+		<-cl.done
+		c.inflightWait.Add(int64(time.Since(start)))
+		return c.adopt(key, cl, store)
+	}
+	timer := time.NewTimer(c.maxLoadWait)
+	defer timer.Stop()
+	select {
+	case <-cl.done:
+		c.inflightWait.Add(int64(time.Since(start)))
+		return c.adopt(key, cl, store)
+	case <-timer.C:
+		c.inflightWait.Add(int64(time.Since(start)))
+	}
+	if !c.loadFallback {
+		return Item[V]{}, ErrLoadTimeout
+	}
+	item, err := c.load(loader)
+	if err != nil {
+		c.logLoadError(key, err)
+		return item, err
+	}
+	return item, store(key, item)
+}
+
+// GetOrComputeMany retrieves the item values mapped to keys from the cache,
+// calling loader once with the keys that are missing or expired to load them
+// in a single round trip. Loaded values are stored with Set and merged into
+// the returned map. Keys loader returns no value for are left out of it.
+//
+// Keys already being loaded by another GetOrCompute or GetOrComputeMany call
+// are not passed to loader; their loads are awaited instead, so overlapping
+// key sets are never loaded twice at once. Loads honor WithMaxLoaders,
+// WithLoadRetry, and WithMaxLoadWait as GetOrCompute's do. If a load fails,
+// the values retrieved so far are returned with the first error. Values the
+// cache has no room for are still returned, with ErrCacheFull. If the cache
+// is closed, missing keys are not loaded and the values retrieved are
+// returned with ErrClosed.
+func (c *Cache[K, V]) GetOrComputeMany(keys []K, loader func(missing []K) (map[K]V, error)) (map[K]V, error) {
+	values := make(map[K]V, len(keys))
+	var missing []K
+	for _, key := range keys {
+		if v, ok := c.getLive(key); ok {
+			values[key] = v
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+	if c.IsClosed() {
+		return values, ErrClosed
+	}
+
+	var mine []K
+	calls := make(map[K]*call[V], len(missing))
+	awaited := make(map[K]*call[V])
+	for _, key := range missing {
+		if _, ok := calls[key]; ok {
+			continue // a duplicate key
+		}
+		cl, leader := c.group.join(key, c)
+		calls[key] = cl
+		if !leader {
+			awaited[key] = cl
+			continue
+		}
+		cl.err = errLoaderPanicked // replaced once loaded
+		mine = append(mine, key)
+	}
+
+	var err error
+	if len(mine) > 0 {
+		err = c.loadMany(mine, calls, loader)
+	}
+	for _, key := range missing {
+		var item Item[V]
+		var loadErr error
+		if cl, ok := awaited[key]; ok {
+			delete(awaited, key) // await a duplicate key once
+			item, loadErr = c.await(key, cl, func() (Item[V], error) {
+				loaded, err := loader([]K{key})
+				if err != nil {
+					return Item[V]{}, err
+				}
+				if v, ok := loaded[key]; ok {
+					return c.newItem(v), nil
+				}
+				return Item[V]{}, ErrNotLoaded
+			}, c.storeItem)
+			calls[key] = &call[V]{item: item, err: loadErr}
+		} else {
+			item, loadErr = calls[key].item, calls[key].err
+		}
+		if loadErr == nil || errors.Is(loadErr, ErrCacheFull) {
+			values[key] = item.Value
+		}
+		if loadErr != nil && !errors.Is(loadErr, ErrNotLoaded) && err == nil {
+			err = loadErr
+		}
+	}
+	return values, err
+}
+
+// loadMany calls loader once with keys, whose in-flight calls are in calls,
+// stores the values it returns, and completes the calls. It returns the
+// loader's error, or else the first error storing a value.
+func (c *Cache[K, V]) loadMany(keys []K, calls map[K]*call[V], loader func([]K) (map[K]V, error)) error {
+	defer func() {
+		for _, key := range keys {
+			c.group.finish(key, calls[key])
+		}
+	}()
+	var loaded map[K]V
+	_, err := c.load(func() (Item[V], error) {
+		var err error
+		loaded, err = loader(keys)
+		return Item[V]{}, err
+	})
+	var storeErr error
+	for _, key := range keys {
+		cl := calls[key]
+		if err != nil {
+			c.logLoadError(key, err)
+			cl.err = err
+			continue
+		}
+		v, ok := loaded[key]
+		if !ok {
+			cl.err = ErrNotLoaded
+			continue
+		}
+		cl.item, cl.err = c.newItem(v), nil
+		if err := c.storeItem(key, cl.item); err != nil && storeErr == nil {
+			storeErr = err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return storeErr
+}
+
+// adopt returns the result of the completed load cl of key, first storing a
+// loaded item with store if cl was made by another cache sharing the cache's
+// Group or by the Group's Do. A value loaded by Do is given the cache's own
+// lifetime for it, as Set would.
+func (c *Cache[K, V]) adopt(key K, cl *call[V], store func(K, Item[V]) error) (Item[V], error) {
+	if cl.err != nil || cl.owner == any(c) {
+		return cl.item, cl.err
+	}
+	item := cl.item
+	if _, ok := cl.owner.(*Cache[K, V]); !ok {
+		item = c.newItem(item.Value)
+	}
+	return item, store(key, item)
+}
+
+// getLive retrieves the item value mapped to key if it is not expired.
+func (c *Cache[K, V]) getLive(key K) (V, bool) {
+	item, ok := c.getLiveItem(key)
+	return item.Value, ok
+}
+
+// getLiveItem retrieves the item mapped to key if it is not expired.
+func (c *Cache[K, V]) getLiveItem(key K) (Item[V], bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	e, ok := c.items[key]
+	if !ok || e.item.expiredAt(c.clock.Now()) {
+		return Item[V]{}, false
+	}
+	e.accessed.Store(c.clock.Now().UnixNano())
+	c.touch(e)
+	return e.item, true
+}
+
+// load calls loader until it succeeds or, if the cache was created
+// WithLoadRetry, its retries are exhausted, sleeping for the backoff between
+// attempts.
+func (c *Cache[K, V]) load(loader func() (Item[V], error)) (Item[V], error) {
+	item, err := c.loadOnce(loader)
+	for attempt := 1; err != nil && attempt <= c.loadRetries; attempt++ {
+		if d := c.loadBackoff(attempt); d > 0 {
+			time.Sleep(d)
+		}
+		item, err = c.loadOnce(loader)
+	}
+	return item, err
+}
+
+// loadOnce calls loader, waiting for a free slot if the cache was created
+// WithMaxLoaders and recording its duration if it was created WithLoadTiming.
+func (c *Cache[K, V]) loadOnce(loader func() (Item[V], error)) (Item[V], error) {
+	if c.loaders != nil {
+		select {
+		case c.loaders <- struct{}{}:
+		default:
+			start := time.Now()
+			c.loaders <- struct{}{}
+			c.loaderWait.Add(int64(time.Since(start)))
+		}
+		defer func() { <-c.loaders }()
+	}
+	if c.loadTimes == nil {
+		return loader()
+	}
+	start := time.Now()
+	defer func() {
+		c.loadTimes.record(time.Since(start))
+	}()
+	return loader()
+}
+
+// ExponentialBackoff returns a backoff for WithLoadRetry that waits base
+// before the first retry and doubles the wait before each further retry, up
+// to max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
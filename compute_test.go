@@ -0,0 +1,431 @@
+package cubby
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestGetOrCompute(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.SetItem("y", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+	cases := map[string]struct {
+		key   string
+		want  int
+		calls int
+	}{
+		"hit":     {key: "x", want: 1, calls: 0},
+		"expired": {key: "y", want: 7, calls: 1},
+		"miss":    {key: "z", want: 7, calls: 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			got, err := cache.GetOrCompute(c.key, func() (int, error) {
+				calls++
+				return 7, nil
+			})
+			if err != nil || got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if calls != c.calls {
+				t.Fatalf(errorString, calls, c.calls)
+			}
+			if v, _ := cache.Get(c.key); v != c.want {
+				t.Fatalf(errorString, v, c.want)
+			}
+		})
+	}
+}
+
+func TestGetOrComputeError(t *testing.T) {
+	cache := NewCache[string, int]()
+	want := errors.New("unavailable")
+	_, err := cache.GetOrCompute("x", func() (int, error) { return 0, want })
+	if !errors.Is(err, want) {
+		t.Fatalf(errorString, err, want)
+	}
+	if _, ok := cache.Get("x"); ok {
+		t.Fatalf("Wanted key x not to be stored but it was")
+	}
+}
+
+func TestGetOrComputeSingleFlight(t *testing.T) {
+	cache := NewCache[string, int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetOrCompute("x", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 7, nil
+			})
+			if err != nil || v != 7 {
+				t.Errorf(errorString, v, 7)
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+}
+
+func TestGetOrComputeDoesNotBlockOtherKeys(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("y", 2)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go cache.GetOrCompute("x", func() (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	<-started
+	done := make(chan struct{})
+	go func() {
+		cache.Get("y")
+		cache.Set("z", 3)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatalf("Access to other keys blocked on a slow loader")
+	}
+	close(release)
+}
+
+func TestGetOrComputeMaxLoadWait(t *testing.T) {
+	cases := map[string]struct {
+		opts    []Option[string, int]
+		want    int
+		wantErr error
+	}{
+		"timeout": {
+			opts:    []Option[string, int]{WithMaxLoadWait[string, int](5 * time.Millisecond)},
+			want:    0,
+			wantErr: ErrLoadTimeout,
+		},
+		"fallback": {
+			opts: []Option[string, int]{
+				WithMaxLoadWait[string, int](5 * time.Millisecond),
+				WithLoadFallback[string, int](),
+			},
+			want:    2,
+			wantErr: nil,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(c.opts...)
+			started := make(chan struct{})
+			release := make(chan struct{})
+			defer close(release)
+			go cache.GetOrCompute("x", func() (int, error) {
+				close(started)
+				<-release
+				return 1, nil
+			})
+			<-started
+			got, err := cache.GetOrCompute("x", func() (int, error) {
+				return 2, nil
+			})
+			if !errors.Is(err, c.wantErr) || got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGetOrComputeMaxLoaders(t *testing.T) {
+	cache := NewCache(WithMaxLoaders[int, int](2))
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache.GetOrCompute(i, func() (int, error) {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond)
+				running.Add(-1)
+				return i, nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	if p := peak.Load(); p != 2 {
+		t.Fatalf(errorString, p, 2)
+	}
+	if cache.Len() != 10 {
+		t.Fatalf(errorString, cache.Len(), 10)
+	}
+}
+
+func TestGetOrComputeRetry(t *testing.T) {
+	errFailed := errors.New("failed")
+	cases := map[string]struct {
+		retries  int
+		failures int
+		calls    int
+		err      error
+	}{
+		"no retries":       {failures: 1, calls: 1, err: errFailed},
+		"recovers":         {retries: 3, failures: 2, calls: 3},
+		"exhausted":        {retries: 2, failures: 5, calls: 3, err: errFailed},
+		"first succeeds":   {retries: 2, calls: 1},
+		"exactly enough":   {retries: 2, failures: 2, calls: 3},
+		"negative retries": {retries: -1, failures: 1, calls: 1, err: errFailed},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var waits []int
+			cache := NewCache(WithLoadRetry[string, int](c.retries, func(attempt int) time.Duration {
+				waits = append(waits, attempt)
+				return 0
+			}))
+			calls := 0
+			_, err := cache.GetOrCompute("x", func() (int, error) {
+				calls++
+				if calls <= c.failures {
+					return 0, errFailed
+				}
+				return 1, nil
+			})
+			if err != c.err {
+				t.Fatalf(errorString, err, c.err)
+			}
+			if calls != c.calls || len(waits) != c.calls-1 {
+				t.Fatalf(errorString, []int{calls, len(waits)}, []int{c.calls, c.calls - 1})
+			}
+			if _, ok := cache.GetItem("x"); ok != (err == nil) {
+				t.Fatalf(errorString, ok, err == nil)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for i, w := range want {
+		if got := backoff(i + 1); got != w*time.Millisecond {
+			t.Fatalf(errorString, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestGetOrComputeNegativeCaching(t *testing.T) {
+	errFailed := errors.New("failed")
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithClock[string, int](clock),
+		WithNegativeCaching[string, int](1*time.Minute),
+	)
+	calls := 0
+	loader := func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errFailed
+		}
+		return calls, nil
+	}
+	steps := []struct {
+		advance time.Duration
+		want    int
+		err     error
+		calls   int
+	}{
+		{err: errFailed, calls: 1},
+		{advance: 30 * time.Second, err: errFailed, calls: 1}, // cached failure
+		{advance: 30 * time.Second, want: 2, calls: 2},        // failure expired
+		{advance: 1 * time.Second, want: 2, calls: 2},         // cached value
+	}
+	for i, s := range steps {
+		clock.Advance(s.advance)
+		v, err := cache.GetOrCompute("x", loader)
+		if v != s.want || err != s.err || calls != s.calls {
+			t.Fatalf("step %d:"+errorString, i, []any{v, err, calls}, []any{s.want, s.err, s.calls})
+		}
+	}
+	if _, err := cache.GetOrCompute("y", loader); err != nil { // other keys unaffected
+		t.Fatalf(errorString, err, nil)
+	}
+}
+
+func TestGetOrComputeItem(t *testing.T) {
+	loaded := Item[int]{Value: 9, CreatedAt: past, ExpiredAt: future}
+	cases := map[string]struct {
+		item  *Item[int]
+		want  Item[int]
+		calls int
+	}{
+		"present": {item: &Item[int]{Value: 1, CreatedAt: now}, want: Item[int]{Value: 1, CreatedAt: now}},
+		"expired": {item: &Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past}, want: loaded, calls: 1},
+		"missing": {want: loaded, calls: 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			if c.item != nil {
+				cache.SetItem("x", *c.item)
+			}
+			calls := 0
+			got, err := cache.GetOrComputeItem("x", func() (Item[int], error) {
+				calls++
+				return loaded, nil
+			})
+			if err != nil || got != c.want || calls != c.calls {
+				t.Fatalf(errorString, []any{got, err, calls}, []any{c.want, nil, c.calls})
+			}
+			if stored, _ := cache.GetItem("x"); stored != c.want {
+				t.Fatalf(errorString, stored, c.want)
+			}
+		})
+	}
+}
+
+func TestGetOrComputeMany(t *testing.T) {
+	errDown := errors.New("backend down")
+	cases := map[string]struct {
+		loaded      map[string]int
+		err         error
+		want        map[string]int
+		wantMissing []string
+		wantErr     error
+	}{
+		"all loaded": {
+			loaded:      map[string]int{"y": 20, "z": 30},
+			want:        map[string]int{"x": 1, "y": 20, "z": 30},
+			wantMissing: []string{"y", "z"},
+		},
+		"some not loaded": {
+			loaded:      map[string]int{"z": 30},
+			want:        map[string]int{"x": 1, "z": 30},
+			wantMissing: []string{"y", "z"},
+		},
+		"error": {
+			err:         errDown,
+			want:        map[string]int{"x": 1},
+			wantMissing: []string{"y", "z"},
+			wantErr:     errDown,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			cache.Set("x", 1)
+			cache.SetItem("y", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+			var calls [][]string
+			got, err := cache.GetOrComputeMany([]string{"x", "y", "z", "z"}, func(missing []string) (map[string]int, error) {
+				calls = append(calls, missing)
+				return c.loaded, c.err
+			})
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf(errorString, err, c.wantErr)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if len(calls) != 1 || !reflect.DeepEqual(calls[0], c.wantMissing) {
+				t.Fatalf(errorString, calls, [][]string{c.wantMissing})
+			}
+			for k, v := range c.loaded {
+				if stored, _ := cache.Get(k); stored != v {
+					t.Fatalf(errorString, stored, v)
+				}
+			}
+		})
+	}
+}
+
+func TestGetOrComputeManyAllCached(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	got, err := cache.GetOrComputeMany([]string{"x"}, func([]string) (map[string]int, error) {
+		t.Fatalf("Wanted loader not to be called for cached keys")
+		return nil, nil
+	})
+	if err != nil || got["x"] != 1 {
+		t.Fatalf(errorString, got, map[string]int{"x": 1})
+	}
+}
+
+func TestGetOrComputeManySingleFlight(t *testing.T) {
+	cache := NewCache[string, int]()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.GetOrCompute("a", func() (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+	var missing []string
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	got, err := cache.GetOrComputeMany([]string{"a", "b"}, func(keys []string) (map[string]int, error) {
+		missing = keys
+		return map[string]int{"b": 2}, nil
+	})
+	<-done
+	if err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if want := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(missing, want) {
+		t.Fatalf(errorString, missing, want)
+	}
+}
+
+func TestGetOrComputeAwaitsMany(t *testing.T) {
+	cache := NewCache[string, int]()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = cache.GetOrComputeMany([]string{"a"}, func([]string) (map[string]int, error) {
+			close(started)
+			<-release
+			return map[string]int{}, nil
+		})
+	}()
+	<-started
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	_, err := cache.GetOrCompute("a", func() (int, error) {
+		t.Errorf("Wanted the in-flight load to be awaited")
+		return 0, nil
+	})
+	if !errors.Is(err, ErrNotLoaded) {
+		t.Fatalf(errorString, err, ErrNotLoaded)
+	}
+}
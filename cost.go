@@ -0,0 +1,22 @@
+package cubby
+
+// SetWithCost adds or updates the item value mapped to key in the cache with
+// the given cost. It otherwise behaves like Set. If the cache was created
+// WithMaxCost, least recently used items are evicted until the item fits. An
+// item whose cost alone exceeds the maximum is not stored, and any item
+// already mapped to key is removed, so the old value is never read in its
+// place.
+func (c *Cache[K, V]) SetWithCost(key K, value V, cost int64) {
+	item := c.newItem(value)
+	c.lock()
+	evicted, _ := c.set(key, item, cost)
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// TotalCost returns the sum of the costs of the items in the cache.
+func (c *Cache[K, V]) TotalCost() int64 {
+	c.rlock()
+	defer c.mu.RUnlock()
+	return c.cost
+}
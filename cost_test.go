@@ -0,0 +1,90 @@
+package cubby
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetWithCost(t *testing.T) {
+	cache := NewCache(WithMaxCost[string, int](10))
+	cache.SetWithCost("x", 1, 4)
+	cache.SetWithCost("y", 2, 4)
+	cache.Set("z", 3)
+	if got := cache.TotalCost(); got != 9 {
+		t.Fatalf(errorString, got, 9)
+	}
+	cache.Get("x") // y is now the least recently used
+	cache.SetWithCost("w", 4, 3)
+	if _, ok := cache.Get("y"); ok {
+		t.Fatalf("Wanted key y to be evicted but it was not")
+	}
+	if got := cache.TotalCost(); got != 8 {
+		t.Fatalf(errorString, got, 8)
+	}
+	cache.SetWithCost("x", 5, 7) // update evicts z to fit
+	if got := cache.TotalCost(); got != 10 {
+		t.Fatalf(errorString, got, 10)
+	}
+	if cache.Len() != 2 {
+		t.Fatalf(errorString, cache.Len(), 2)
+	}
+	cache.Delete("x")
+	if got := cache.TotalCost(); got != 3 {
+		t.Fatalf(errorString, got, 3)
+	}
+}
+
+func TestSetWithCostTooLarge(t *testing.T) {
+	var evicted []string
+	cache := NewCache(
+		WithMaxCost[string, int](10),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			evicted = append(evicted, key)
+		}),
+	)
+	cache.SetWithCost("x", 1, 5)
+	cache.SetWithCost("y", 2, 11)
+	if _, ok := cache.Get("y"); ok {
+		t.Fatalf("Wanted key y not to be stored but it was")
+	}
+	if _, ok := cache.Get("x"); !ok {
+		t.Fatalf("Wanted key x to be in cache but it was not")
+	}
+	if len(evicted) != 1 || evicted[0] != "y" {
+		t.Fatalf(errorString, evicted, []string{"y"})
+	}
+}
+
+func TestSetWithCostTooLargeUpdate(t *testing.T) {
+	var evicted []int
+	cache := NewCache(
+		WithMaxCost[string, int](10),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			evicted = append(evicted, value)
+		}),
+	)
+	cache.SetWithCost("x", 1, 5)
+	cache.SetWithCost("x", 2, 11)
+	if v, ok := cache.Get("x"); ok {
+		t.Fatalf("Got stale value %d for key x but wanted it missing", v)
+	}
+	if got := cache.TotalCost(); got != 0 {
+		t.Fatalf(errorString, got, 0)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(evicted, want) {
+		t.Fatalf(errorString, evicted, want)
+	}
+}
+
+func TestTotalCostUnbounded(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.SetWithCost("x", 1, 100)
+	cache.Set("y", 2)
+	if got := cache.TotalCost(); got != 101 {
+		t.Fatalf(errorString, got, 101)
+	}
+	cache.Clear()
+	if got := cache.TotalCost(); got != 0 {
+		t.Fatalf(errorString, got, 0)
+	}
+}
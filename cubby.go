@@ -1,7 +1,14 @@
 package cubby
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -10,140 +17,1105 @@ type Item[V any] struct {
 	Value     V
 	CreatedAt time.Time
 	ExpiredAt time.Time
+
+	// Pinned items are never evicted to make room in a bounded cache. They
+	// are still removed by Delete, Clear, and, once expired, ClearExpired.
+	Pinned bool
 }
 
-// IsExpired returns true if time now is past the item's set ExpiredAt date.
+// IsExpired returns true if time now is at or past the item's set ExpiredAt
+// date.
 func (i *Item[V]) IsExpired() bool {
-	return !i.ExpiredAt.IsZero() && time.Now().UTC().After(i.ExpiredAt)
+	return i.expiredAt(time.Now().UTC())
+}
+
+// expiredAt returns true if t is at or past the item's set ExpiredAt date.
+func (i *Item[V]) expiredAt(t time.Time) bool {
+	return expiredBy(i.ExpiredAt, t)
+}
+
+// expiredBy returns true if t is at or past the expiration date expiredAt.
+// A zero expiredAt never expires.
+func expiredBy(expiredAt, t time.Time) bool {
+	return !expirationPaused.Load() && !expiredAt.IsZero() && !t.Before(expiredAt)
+}
+
+// expirationPaused is set while expiration is paused for every item.
+var expirationPaused atomic.Bool
+
+// PauseExpiration makes every item in every cache live until
+// ResumeExpiration is called: IsExpired reports false and ClearExpired
+// removes nothing, so a TickingCache sweep is a no-op. ExpiredAt dates are
+// unchanged. It is intended for debugging.
+func PauseExpiration() {
+	expirationPaused.Store(true)
+}
+
+// ResumeExpiration undoes PauseExpiration. Items whose ExpiredAt dates passed
+// while expiration was paused are expired again immediately.
+func ResumeExpiration() {
+	expirationPaused.Store(false)
+}
+
+// EvictReason describes why an item was removed from a Cache.
+type EvictReason int
+
+const (
+	// EvictDeleted indicates the item was removed by Delete.
+	EvictDeleted EvictReason = iota
+	// EvictCleared indicates the item was removed by Clear.
+	EvictCleared
+	// EvictExpired indicates the item was removed because it expired.
+	EvictExpired
+	// EvictCapacity indicates the item was removed to make room for another.
+	EvictCapacity
+)
+
+// String returns the name of the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictDeleted:
+		return "deleted"
+	case EvictCleared:
+		return "cleared"
+	case EvictExpired:
+		return "expired"
+	case EvictCapacity:
+		return "capacity"
+	}
+	return "unknown"
+}
+
+// entry wraps an Item with the bookkeeping a Cache needs to manage it.
+type entry[K comparable, V any] struct {
+	key        K
+	item       Item[V]
+	prev, next *entry[K, V] // neighbors in the LRU list, if the cache has one
+	pos        int          // index in the cache's order
+	cost       int64
+	accessed   atomic.Int64      // UnixNano of the last read, or 0 if never read
+	meta       map[string]any    // set by SetWithMeta
+	tier       string            // set by SetInTier
+	recipe     func() (V, error) // set by SetRecomputable
+}
+
+// eviction records an item removed from a Cache for its OnEvict callback.
+type eviction[K comparable, V any] struct {
+	key    K
+	item   Item[V]
+	reason EvictReason
 }
 
 // Cache represents a generic store that wraps a map of a comparable type to
 // an Item with a value of any type and a mutex for concurrent access.
 type Cache[K comparable, V any] struct {
-	items map[K]Item[V]
+	items map[K]*entry[K, V]
 	mu    sync.RWMutex
+	id    uint64       // orders locking across caches; see Move
+	count atomic.Int64 // len(items), for ttlScale to read without the lock
+
+	// order holds every entry in the order they were added, but for
+	// entries moved into the gaps of removed ones, so that Sample can draw
+	// entries at random without copying them.
+	order []*entry[K, V]
+
+	// lru orders entries from most to least recently used. It is nil unless
+	// the cache is bounded. lruMu guards moves made under the read lock.
+	lru   *lruList[K, V]
+	lruMu sync.Mutex
+
+	name           string
+	ttl            time.Duration
+	ttlFunc        func(V) time.Duration
+	slidingTTL     time.Duration
+	maxLifetime    time.Duration
+	lazyExpiration bool // reads remove expired items
+	noExpiration   bool // items never expire
+	closed         bool // set by Close; writes are dropped
+	staleGrace     time.Duration
+	staleReads     atomic.Int64
+	contention     bool // count lock acquisitions that wait
+	finalize       bool // evict remaining items when unreachable
+	lockWaits      atomic.Int64
+	rlockWaits     atomic.Int64
+	capacity       int
+	rejectWhenFull bool // refuse new keys at capacity rather than evict
+	rejected       atomic.Int64
+	softLimit      int
+	maxCost        int64
+	cost           int64
+	clock          Clock
+	logger         *slog.Logger
+	keyCodec       *keyCodec[K]
+	weigher        func(key K, value V) int64
+	hasher         func(V) uint64
+	rand           *rand.Rand // set by WithRand; guarded by randMu
+	randMu         sync.Mutex
+	onEvict        func(ctx context.Context, key K, value V, reason EvictReason)
+	onSweep        func(expired map[K]Item[V])
+	onLoad         func(key K, item *Item[V])
+	onBeforeEvict  func(key K, item Item[V]) bool
+
+	// group deduplicates in-flight GetOrCompute loads, and loads tracks
+	// their failures. They are locked separately so loaders run without
+	// holding mu.
+	group        *Group[K, V]
+	loads        inflight[K, V]
+	maxLoadWait  time.Duration
+	loadFallback bool
+	loadRetries  int
+	loadBackoff  func(attempt int) time.Duration
+	negativeTTL  time.Duration
+	loadTimes    *loadTimes    // nil unless timing loads
+	loaders      chan struct{} // semaphore of loader slots, nil if unlimited
+	loaderWait   atomic.Int64  // nanoseconds spent waiting for a loader slot
+	inflightWait atomic.Int64  // nanoseconds spent waiting on in-flight loads
+
+	// keyLocks are the stripes of per-key locks taken by LockKey and Update.
+	keyLocks [keyLockStripes]sync.Mutex
+
+	// waiters maps keys to the channels of goroutines blocked in Wait.
+	waiters map[K][]chan V
+
+	// expired receives swept items once ExpireNotify has been called.
+	expired chan Item[V]
+
+	// debounced maps keys to values waiting to be stored by SetDebounced.
+	debounceMu sync.Mutex
+	debounced  map[K]*debounced[V]
+
+	// subs holds the subscriptions made with Subscribe.
+	subs subscribers[K, V]
+
+	// tiers maps the names of TTL tiers declared WithTier to their
+	// lifetimes, and tierKeys maps them to the keys set in them.
+	tiers    map[string]time.Duration
+	tierKeys map[string]map[K]struct{}
+
+	// indexes maps the names of secondary indexes added with AddIndex to
+	// them.
+	indexes map[string]*secondaryIndex[K, V]
+
+	// recipes maps keys set with SetRecomputable that were evicted for
+	// capacity to the recipes that rebuild their values.
+	recipes map[K]recipe[V]
+}
+
+// ErrInvalidItem is returned by SetItemChecked for an item that expires
+// before it is created.
+var ErrInvalidItem = errors.New("cubby: item expires before it is created")
+
+// SetItemChecked is like SetItem but returns ErrInvalidItem, without storing
+// item, if item has an ExpiredAt date before its CreatedAt date, ErrCacheFull
+// if the cache has no room for item, and ErrClosed if the cache is closed. A
+// zero CreatedAt date is set to time now before the dates are checked.
+func (c *Cache[K, V]) SetItemChecked(key K, item Item[V]) error {
+	item = c.created(item)
+	if !item.ExpiredAt.IsZero() && item.ExpiredAt.Before(item.CreatedAt) {
+		return fmt.Errorf("%w: created at %v, expired at %v",
+			ErrInvalidItem, item.CreatedAt, item.ExpiredAt)
+	}
+	return c.storeItem(key, item)
 }
 
-// SetItem adds or updates the item mapped to key in the cache.
+// SetItem adds or updates the item mapped to key in the cache. If item has a
+// zero CreatedAt date, it is set to time now, so every stored item has a real
+// creation time. If the cache is at capacity, the least recently used
+// unpinned item is evicted to make room. If every other item is pinned, item
+// is dropped instead.
 func (c *Cache[K, V]) SetItem(key K, item Item[V]) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.items[key] = item
+	c.setItem(context.Background(), key, item)
+}
+
+// created returns item with its CreatedAt date set to time now if it is
+// zero.
+func (c *Cache[K, V]) created(item Item[V]) Item[V] {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = c.clock.Now()
+	}
+	return item
+}
+
+// setItem is SetItem with ctx passed to the OnEvict callback.
+func (c *Cache[K, V]) setItem(ctx context.Context, key K, item Item[V]) {
+	c.lock()
+	evicted, _ := c.set(key, item, 1)
+	c.mu.Unlock()
+	c.notifyContext(ctx, evicted)
+}
+
+// storeItem is SetItem but returns ErrClosed if the cache is closed and
+// ErrCacheFull if it has no room for item.
+func (c *Cache[K, V]) storeItem(key K, item Item[V]) error {
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	evicted, stored := c.set(key, item, 1)
+	c.mu.Unlock()
+	c.notify(evicted)
+	if !stored {
+		return ErrCacheFull
+	}
+	return nil
 }
 
 // Set adds or updates the item value mapped to key in the cache. CreatedAt is
-// always set to time now.
+// always set to time now. If the cache has a default TTL or TTL function, the
+// item expires after the lifetime it gives.
 func (c *Cache[K, V]) Set(key K, value V) {
-	c.SetItem(key, Item[V]{
-		Value:     value,
-		CreatedAt: time.Now().UTC(),
-	})
+	c.SetItem(key, c.newItem(value))
+}
+
+// SetContext is like Set but passes ctx to the cache's WithOnEvictContext
+// callback for any items evicted to make room.
+func (c *Cache[K, V]) SetContext(ctx context.Context, key K, value V) {
+	c.setItem(ctx, key, c.newItem(value))
 }
 
 // SetToExpire adds or updates the item value with an expiration date equal to
-// time now + lifetime mapped to key in the cache.
+// time now + lifetime mapped to key in the cache. If lifetime is not positive,
+// the item is expired immediately. In a cache created WithoutExpiration, it
+// stores the item without an expiration date, like Set.
 func (c *Cache[K, V]) SetToExpire(key K, value V, lifetime time.Duration) {
-	now := time.Now().UTC()
+	now := c.clock.Now()
 	c.SetItem(key, Item[V]{
 		Value:     value,
 		CreatedAt: now,
-		ExpiredAt: now.Add(lifetime),
+		ExpiredAt: now.Add(c.scaled(lifetime)),
+	})
+}
+
+// SetToExpireIfLonger is like SetToExpire but never shortens the lifetime of
+// an unexpired item already mapped to key: the expiration date is set to time
+// now + lifetime only if key is missing or expired or that date is later
+// than the existing one. In either case value replaces the existing value, so
+// a late writer with a short lifetime still updates the value but keeps the
+// longer lease. It returns true if the expiration date was set or extended,
+// and false, storing nothing, if the cache is closed or has no room for value.
+// An item that never
+// expires is never given an expiration date.
+func (c *Cache[K, V]) SetToExpireIfLonger(key K, value V, lifetime time.Duration) bool {
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return false
+	}
+	now := c.clock.Now()
+	item := Item[V]{Value: value, CreatedAt: now, ExpiredAt: now.Add(c.scaled(lifetime))}
+	extended := true
+	if e, ok := c.items[key]; ok && !e.item.expiredAt(now) &&
+		(e.item.ExpiredAt.IsZero() || !item.ExpiredAt.After(e.item.ExpiredAt)) {
+		item.ExpiredAt = e.item.ExpiredAt
+		extended = false
+	}
+	evicted, stored := c.set(key, item, 1)
+	c.mu.Unlock()
+	c.notify(evicted)
+	return extended && stored
+}
+
+// GetOrSetToExpire retrieves the item value mapped to key from the cache if it
+// is present and not expired, leaving its expiration date unchanged, and
+// returns true. Otherwise, it adds the item value with an expiration date
+// equal to time now + lifetime and returns it and false. If value is not
+// stored, because the cache is closed or has no room for it, the zero value
+// is returned with false.
+func (c *Cache[K, V]) GetOrSetToExpire(key K, value V, lifetime time.Duration) (V, bool) {
+	return c.getOrSet(key, func(now time.Time) Item[V] {
+		return Item[V]{Value: value, CreatedAt: now, ExpiredAt: now.Add(c.scaled(lifetime))}
 	})
 }
 
-// GetItem retrieves the item mapped to key from the cache.
+// LoadOrStore retrieves the item value mapped to key from the cache if it is
+// present and not expired and returns true as loaded. Otherwise, it adds
+// value, as Set would, and returns it and false. Its signature mirrors
+// sync.Map's; it is the canonical way to get a value or store a default in
+// one operation. If value is not stored, because the cache is closed or has
+// no room for it, the zero value is returned with false, so actual is always
+// a value that was in the cache.
+func (c *Cache[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	return c.getOrSet(key, func(time.Time) Item[V] {
+		return c.newItem(value)
+	})
+}
+
+// getOrSet retrieves the item value mapped to key if it is present and not
+// expired, and otherwise adds the item newItem returns for time now and
+// returns its value, or the zero value if it was not stored.
+func (c *Cache[K, V]) getOrSet(key K, newItem func(now time.Time) Item[V]) (V, bool) {
+	c.lock()
+	now := c.clock.Now()
+	if e, ok := c.items[key]; ok && !e.item.expiredAt(now) {
+		e.accessed.Store(now.UnixNano())
+		c.touch(e)
+		v := e.item.Value
+		c.mu.Unlock()
+		return v, true
+	}
+	var zero V
+	if c.closed {
+		c.mu.Unlock()
+		return zero, false
+	}
+	item := newItem(now)
+	evicted, stored := c.set(key, item, 1)
+	c.mu.Unlock()
+	c.notify(evicted)
+	if !stored {
+		return zero, false
+	}
+	return item.Value, false
+}
+
+// GetAndTouch retrieves the item value mapped to key from the cache if it is
+// present and not expired and, in the same operation, sets its expiration
+// date to time now + lifetime. In a cache created WithoutExpiration, the
+// item keeps never expiring. It returns false, leaving the item as is, if the
+// cache is closed.
+func (c *Cache[K, V]) GetAndTouch(key K, lifetime time.Duration) (V, bool) {
+	c.lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	e, ok := c.items[key]
+	if !ok || e.item.expiredAt(now) || c.closed {
+		var zero V
+		return zero, false
+	}
+	if !c.noExpiration {
+		e.item.ExpiredAt = now.Add(c.scaled(lifetime))
+	}
+	e.accessed.Store(now.UnixNano())
+	c.touch(e)
+	return e.item.Value, true
+}
+
+// GetItem retrieves the item mapped to key from the cache. By default,
+// expired items are retrieved like any other until they are removed by
+// ClearExpired. If the cache was created WithLazyExpiration, an expired item
+// is removed instead and reported as missing.
 func (c *Cache[K, V]) GetItem(key K) (Item[V], bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	item, ok := c.items[key]
+	var item Item[V]
+	var ok bool
+	if c.slidingTTL > 0 {
+		item, ok = c.readSliding(key)
+	} else {
+		c.rlock()
+		item, ok = c.read(key)
+		c.mu.RUnlock()
+	}
+	if !ok || (!c.lazyExpiration && c.staleGrace == 0) {
+		return item, ok
+	}
+	if c.lapsed(key, item.ExpiredAt) {
+		return Item[V]{}, false
+	}
+	return item, ok
+}
+
+// GetItemCopy is like GetItem but returns an item whose value is the result
+// of copyValue, which is called with the cached value while the read lock is
+// held. For values of reference types, such as slices and maps, copyValue
+// should return a deep copy, so that mutating the returned value does not
+// change the cached one. copyValue must not call methods of the cache that
+// write, or it will deadlock.
+func (c *Cache[K, V]) GetItemCopy(key K, copyValue func(V) V) (Item[V], bool) {
+	c.rlock()
+	item, ok := c.read(key)
+	if ok {
+		item.Value = copyValue(item.Value)
+	}
+	c.mu.RUnlock()
+	if ok && (c.lazyExpiration || c.staleGrace > 0) && c.lapsed(key, item.ExpiredAt) {
+		return Item[V]{}, false
+	}
 	return item, ok
 }
 
-// Get retrieves the item value mapped to key from the cache.
+// lapsed handles the read of an item mapped to key that expires at expiredAt
+// for a cache created WithLazyExpiration or WithStaleReadTracking. If the
+// item is expired, it counts a stale read and, if expiration is lazy, removes
+// the item and returns true to report it missing.
+func (c *Cache[K, V]) lapsed(key K, expiredAt time.Time) bool {
+	now := c.clock.Now()
+	if !expiredBy(expiredAt, now) {
+		return false
+	}
+	if c.staleGrace > 0 && (c.lazyExpiration || now.Sub(expiredAt) <= c.staleGrace) {
+		c.staleReads.Add(1)
+	}
+	if c.lazyExpiration {
+		c.expire(key)
+		return true
+	}
+	return false
+}
+
+// LastAccessed returns when the item mapped to key was last retrieved with
+// Get or GetItem, or its CreatedAt date if it was never retrieved.
+func (c *Cache[K, V]) LastAccessed(key K) (time.Time, bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	e, ok := c.items[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	if n := e.accessed.Load(); n != 0 {
+		return time.Unix(0, n).In(e.item.CreatedAt.Location()), true
+	}
+	return e.item.CreatedAt, true
+}
+
+// Get retrieves the item value mapped to key from the cache. Expired items
+// are treated as GetItem treats them. Only the value and expiration date are
+// read, so Get avoids copying the whole item out of the cache.
 func (c *Cache[K, V]) Get(key K) (V, bool) {
-	item, ok := c.GetItem(key)
-	return item.Value, ok
+	if c.slidingTTL > 0 {
+		item, ok := c.GetItem(key)
+		if !ok {
+			return c.recompute(key)
+		}
+		return item.Value, true
+	}
+	c.rlock()
+	e, ok := c.items[key]
+	if !ok {
+		c.mu.RUnlock()
+		return c.recompute(key)
+	}
+	e.accessed.Store(c.clock.Now().UnixNano())
+	c.touch(e)
+	v, expiredAt := e.item.Value, e.item.ExpiredAt
+	c.mu.RUnlock()
+	if c.noExpiration || (!c.lazyExpiration && c.staleGrace == 0) {
+		return v, true
+	}
+	if c.lapsed(key, expiredAt) {
+		return c.recompute(key)
+	}
+	return v, true
+}
+
+// GetOrZero retrieves the item value mapped to key from the cache, or the
+// zero value of V if key is missing or expired. It suits values whose zero
+// value is a meaningful default, such as counters.
+func (c *Cache[K, V]) GetOrZero(key K) V {
+	v, _ := c.getLive(key)
+	return v
+}
+
+// GetStale retrieves the item value mapped to key from the cache and reports
+// whether it is stale, i.e. expired. Unlike Get, it returns expired item
+// values in every mode, so callers can serve stale values while refreshing
+// them.
+func (c *Cache[K, V]) GetStale(key K) (value V, stale bool, ok bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	item, ok := c.read(key)
+	return item.Value, ok && item.expiredAt(c.clock.Now()), ok
 }
 
 // Delete removes the item mapped to key from the cache.
 func (c *Cache[K, V]) Delete(key K) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	delete(c.items, key)
+	c.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext is like Delete but passes ctx to the cache's
+// WithOnEvictContext callback.
+func (c *Cache[K, V]) DeleteContext(ctx context.Context, key K) {
+	c.lock()
+	e, ok := c.remove(key)
+	c.mu.Unlock()
+	if ok && c.observed() {
+		c.notifyContext(ctx, []eviction[K, V]{{key, e.item, EvictDeleted}})
+	}
+}
+
+// DeleteIf removes the item mapped to key from the cache only if pred returns
+// true for its value, checking and removing it in one operation so that no
+// other write can come between them, and reports whether it was removed. It
+// suits deleting a lock entry only while still holding it. pred is called
+// with the write lock held and must not call methods of the cache.
+func (c *Cache[K, V]) DeleteIf(key K, pred func(value V) bool) bool {
+	c.lock()
+	e, ok := c.items[key]
+	if !ok || !pred(e.item.Value) {
+		c.mu.Unlock()
+		return false
+	}
+	c.remove(key)
+	c.mu.Unlock()
+	if c.observed() {
+		c.notify([]eviction[K, V]{{key, e.item, EvictDeleted}})
+	}
+	return true
 }
 
-// Clear removes all items from the cache.
+// DeleteMany removes the items mapped to keys from the cache and returns how
+// many were present.
+func (c *Cache[K, V]) DeleteMany(keys []K) int {
+	var evicted []eviction[K, V]
+	n := 0
+	c.lock()
+	for _, key := range keys {
+		if e, ok := c.remove(key); ok {
+			n++
+			if c.observed() {
+				evicted = append(evicted, eviction[K, V]{key, e.item, EvictDeleted})
+			}
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return n
+}
+
+// Clear removes all items from the cache, after storing any values pending
+// from SetDebounced.
 func (c *Cache[K, V]) Clear() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.items = make(map[K]Item[V])
+	c.Flush()
+	c.lock()
+	old := c.reset(0)
+	c.mu.Unlock()
+	if !c.observed() {
+		return
+	}
+	evicted := make([]eviction[K, V], 0, len(old))
+	for key, e := range old {
+		evicted = append(evicted, eviction[K, V]{key, e.item, EvictCleared})
+	}
+	c.notify(evicted)
+}
+
+// ClearExcept removes all items from the cache except those for which keep
+// returns true, after storing any values pending from SetDebounced, and
+// returns how many it removed. keep is called with the write lock held and
+// must not call methods of the cache.
+func (c *Cache[K, V]) ClearExcept(keep func(key K, item Item[V]) bool) int {
+	c.Flush()
+	var evicted []eviction[K, V]
+	n := 0
+	c.lock()
+	for key, e := range c.items {
+		if keep(key, e.item) {
+			continue
+		}
+		c.remove(key)
+		n++
+		if c.observed() {
+			evicted = append(evicted, eviction[K, V]{key, e.item, EvictCleared})
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return n
 }
 
-// ClearExpired removes all expired items from the cache.
+// SwapAll replaces all items in the cache with items in one operation and
+// returns the items it replaced. The replaced items are not passed to the
+// OnEvict callback; the caller owns them. Items beyond the cache's bounds are
+// evicted as if set one at a time. If the cache is closed, it returns nil and
+// leaves the cache as is.
+func (c *Cache[K, V]) SwapAll(items map[K]Item[V]) map[K]Item[V] {
+	var evicted []eviction[K, V]
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	old := c.reset(len(items))
+	for k, item := range items {
+		ev, _ := c.set(k, item, 1)
+		evicted = append(evicted, ev...)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	replaced := make(map[K]Item[V], len(old))
+	for k, e := range old {
+		replaced[k] = e.item
+	}
+	return replaced
+}
+
+// ClearExpired removes all expired items from the cache. Every item is
+// compared against a single reading of the cache's clock, so a sweep is
+// consistent however long it takes. If the cache was created WithOnSweep and
+// any items were removed, the callback is then called once with all of them.
 func (c *Cache[K, V]) ClearExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	for key, item := range c.items {
-		if item.IsExpired() {
-			delete(c.items, key)
+	c.clearExpired(-1)
+}
+
+// ClearExpiredN is like ClearExpired but removes at most limit expired items
+// and returns how many it removed. Sweeping a large cache in several calls
+// holds the lock in shorter bursts, keeping readers from waiting long. A
+// non-positive limit removes nothing.
+func (c *Cache[K, V]) ClearExpiredN(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return c.clearExpired(limit)
+}
+
+// clearExpired removes up to limit expired items, or all of them if limit is
+// negative, and returns how many it removed.
+func (c *Cache[K, V]) clearExpired(limit int) int {
+	if c.noExpiration {
+		return 0
+	}
+	c.lock()
+	now := c.clock.Now()
+	expired := c.expired
+	var evicted []eviction[K, V]
+	n := 0
+	for key, e := range c.items {
+		if n == limit {
+			break
+		}
+		if e.item.expiredAt(now) {
+			c.remove(key)
+			n++
+			if c.observed() || c.onSweep != nil || expired != nil {
+				evicted = append(evicted, eviction[K, V]{key, e.item, EvictExpired})
+			}
+		}
+	}
+	if limit < 0 {
+		c.dropExpiredRecipes(now)
+	}
+	c.mu.Unlock()
+	c.swept(expired, evicted)
+	return n
+}
+
+// swept reports the items removed by a sweep to the OnEvict callback and
+// subscribers, ExpireNotify channel, and OnSweep callback.
+func (c *Cache[K, V]) swept(expired chan Item[V], evicted []eviction[K, V]) {
+	c.notify(evicted)
+	c.logSweep(len(evicted))
+	emitExpired(expired, evicted)
+	if c.onSweep != nil && len(evicted) > 0 {
+		swept := make(map[K]Item[V], len(evicted))
+		for _, ev := range evicted {
+			swept[ev.key] = ev.item
 		}
+		c.onSweep(swept)
 	}
 }
 
-// Items returns a copy of the items map.
+// expire removes the item mapped to key if it is expired.
+func (c *Cache[K, V]) expire(key K) {
+	c.lock()
+	e, ok := c.items[key]
+	if !ok || !e.item.expiredAt(c.clock.Now()) {
+		c.mu.Unlock()
+		return
+	}
+	c.remove(key)
+	expired := c.expired
+	c.mu.Unlock()
+	evicted := []eviction[K, V]{{key, e.item, EvictExpired}}
+	c.notify(evicted)
+	emitExpired(expired, evicted)
+}
+
+// ExpiredKeys returns the keys of all expired items in the cache without
+// removing them.
+func (c *Cache[K, V]) ExpiredKeys() []K {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	var keys []K
+	for k, e := range c.items {
+		if e.item.expiredAt(now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Items returns a copy of the items map. The copy is made under a single
+// read lock, so it is a point-in-time snapshot even under concurrent writes.
 func (c *Cache[K, V]) Items() map[K]Item[V] {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 	items := make(map[K]Item[V], len(c.items))
-	for k, v := range c.items {
-		items[k] = v
+	for k, e := range c.items {
+		items[k] = e.item
 	}
 	return items
 }
 
+// ToMap returns a map of keys to the values of all unexpired items in the
+// cache. The map is a copy, so changing it does not affect the cache.
+func (c *Cache[K, V]) ToMap() map[K]V {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	values := make(map[K]V, len(c.items))
+	for k, e := range c.items {
+		if !e.item.expiredAt(now) {
+			values[k] = e.item.Value
+		}
+	}
+	return values
+}
+
+// UpdateAll calls fn with the key and value of every unexpired item in the
+// cache while holding the write lock, replacing the value with the one fn
+// returns if fn also returns true. It returns how many values were replaced.
+// The items keep their dates. If the cache is closed, fn is not called and it
+// returns 0. fn must not call methods of the cache, or it will deadlock.
+func (c *Cache[K, V]) UpdateAll(fn func(key K, value V) (V, bool)) int {
+	c.lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0
+	}
+	now := c.clock.Now()
+	n := 0
+	for k, e := range c.items {
+		if e.item.expiredAt(now) {
+			continue
+		}
+		if v, ok := fn(k, e.item.Value); ok {
+			c.setValue(e, v)
+			n++
+		}
+	}
+	return n
+}
+
+// eachLive calls fn with the value of every unexpired item in the cache while
+// holding the read lock.
+func (c *Cache[K, V]) eachLive(fn func(V)) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	for _, e := range c.items {
+		if !e.item.expiredAt(now) {
+			fn(e.item.Value)
+		}
+	}
+}
+
 // Len returns the length of the items map in the cache.
 func (c *Cache[K, V]) Len() int {
-	c.mu.RLock()
+	c.rlock()
 	defer c.mu.RUnlock()
 	return len(c.items)
 }
 
-// NewCache creates a Cache with K type keys and V type values.
-func NewCache[K comparable, V any]() *Cache[K, V] {
-	return &Cache[K, V]{
-		items: make(map[K]Item[V]),
+// Name returns the name the cache was created with, if any.
+func (c *Cache[K, V]) Name() string {
+	return c.name
+}
+
+// modify replaces the item value mapped to key with fn of it and returns the
+// result. A missing or expired key is given fn of the zero value in a new
+// item, as added by Set.
+func (c *Cache[K, V]) modify(key K, fn func(V) V) V {
+	return c.modifyOr(key, fn, c.newItem)
+}
+
+// modifyOr is like modify but a missing or expired key is given the item
+// newItem returns for fn of the zero value.
+func (c *Cache[K, V]) modifyOr(key K, fn func(V) V, newItem func(V) Item[V]) V {
+	c.lock()
+	if e, ok := c.items[key]; ok && !e.item.expiredAt(c.clock.Now()) {
+		c.setValue(e, fn(e.item.Value))
+		c.touch(e)
+		v := e.item.Value
+		c.mu.Unlock()
+		return v
+	}
+	var zero V
+	item := newItem(fn(zero))
+	evicted, _ := c.set(key, item, 1)
+	c.mu.Unlock()
+	c.notify(evicted)
+	return item.Value
+}
+
+// newItem returns an item holding value that is created time now and expires
+// after the cache's default lifetime for value, if any.
+func (c *Cache[K, V]) newItem(value V) Item[V] {
+	now := c.clock.Now()
+	item := Item[V]{Value: value, CreatedAt: now}
+	lifetime := c.ttl
+	if c.ttlFunc != nil {
+		lifetime = c.ttlFunc(value)
+	}
+	if c.slidingTTL > 0 {
+		lifetime = c.slidingTTL
+		if c.maxLifetime > 0 {
+			lifetime = min(lifetime, c.maxLifetime)
+		}
+	}
+	if lifetime > 0 {
+		item.ExpiredAt = now.Add(c.scaled(lifetime))
+	}
+	return item
+}
+
+// set maps item with the given cost to key and returns the items evicted to
+// make room for it and whether item was stored. The item keeps its dates. An
+// item whose cost alone exceeds the cache's maximum cost, or for which no room
+// can be made because every other item is pinned, is not stored and is returned
+// as evicted instead; the item it would have replaced is removed and returned
+// as evicted with it. A new key refused by a full cache created
+// WithRejectWhenFull is not stored either. An updated item keeps its pin. A
+// zero CreatedAt date is set to time now, whichever write stores the item.
+// Nothing is stored once the cache is closed. The caller must hold the write
+// lock.
+func (c *Cache[K, V]) set(key K, item Item[V], cost int64) ([]eviction[K, V], bool) {
+	if c.closed {
+		return nil, false
+	}
+	item = c.created(item)
+	if c.maxCost > 0 && cost > c.maxCost {
+		var evicted []eviction[K, V]
+		if e, ok := c.remove(key); ok {
+			evicted = append(evicted, eviction[K, V]{key, e.item, EvictCapacity})
+		}
+		return append(evicted, eviction[K, V]{key, item, EvictCapacity}), false
+	}
+	if c.noExpiration {
+		item.ExpiredAt = time.Time{}
 	}
+	if !c.admits(key) {
+		c.rejected.Add(1)
+		return nil, false
+	}
+	if e, ok := c.items[key]; ok {
+		item.Pinned = item.Pinned || e.item.Pinned
+		c.cost += cost - e.cost
+		c.unindex(e)
+		e.item, e.cost, e.meta, e.recipe = item, cost, nil, nil
+		c.index(e)
+		c.untier(key, e)
+		c.touch(e)
+	} else {
+		e := &entry[K, V]{key: key, item: item, cost: cost}
+		if c.lru != nil {
+			c.lru.pushFront(e)
+		}
+		c.items[key] = e
+		e.pos = len(c.order)
+		c.order = append(c.order, e)
+		c.count.Add(1)
+		c.cost += cost
+		delete(c.recipes, key)
+		c.index(e)
+		c.wake(key, item.Value)
+	}
+	var evicted []eviction[K, V]
+	for c.overBounds() {
+		k, ok := c.victim(key)
+		if !ok {
+			k = key
+		}
+		e, _ := c.unlink(k)
+		evicted = append(evicted, eviction[K, V]{k, e.item, EvictCapacity})
+		if !ok {
+			return evicted, false
+		}
+	}
+	return evicted, true
 }
 
-// TickingCache extends Cache with functionality to process a job at every
-// interval. A common application is to clear expired entries at every tick.
-type TickingCache[K comparable, V any] struct {
-	*Cache[K, V]
-	ticker *time.Ticker
-	Job    func()
+// victim returns the least recently used unpinned key other than skip whose
+// eviction the OnBeforeEvict callback, if any, allows. If the callback vetoes
+// every candidate, the least recently used one is returned regardless. The
+// caller must hold the write lock.
+func (c *Cache[K, V]) victim(skip K) (K, bool) {
+	var first K
+	found := false
+	for e := c.lru.back(); e != nil; e = c.lru.prev(e) {
+		k := e.key
+		if k == skip || e.item.Pinned {
+			continue
+		}
+		if c.onBeforeEvict == nil || c.onBeforeEvict(k, e.item) {
+			return k, true
+		}
+		if !found {
+			first, found = k, true
+		}
+	}
+	return first, found
+}
+
+// ttlScale returns the factor by which the lifetimes of items set now are
+// scaled: the cache's soft limit divided by its length once it exceeds the
+// limit, and 1 otherwise.
+func (c *Cache[K, V]) ttlScale() float64 {
+	n := c.count.Load()
+	if c.softLimit <= 0 || n <= int64(c.softLimit) {
+		return 1
+	}
+	return float64(c.softLimit) / float64(n)
+}
+
+// scaled returns lifetime scaled by ttlScale, for an item given a new
+// lifetime now. Items that keep their dates, such as moved or loaded ones,
+// are not scaled.
+func (c *Cache[K, V]) scaled(lifetime time.Duration) time.Duration {
+	if scale := c.ttlScale(); scale < 1 && lifetime > 0 {
+		return time.Duration(float64(lifetime) * scale)
+	}
+	return lifetime
+}
+
+// overBounds returns true if the cache holds more items or more cost than it
+// is bounded to. The caller must hold the write lock.
+func (c *Cache[K, V]) overBounds() bool {
+	return (c.capacity > 0 && len(c.items) > c.capacity) ||
+		(c.maxCost > 0 && c.cost > c.maxCost)
 }
 
-// Start creates a new ticker and calls Job at every tick denoted by duration.
-func (tc *TickingCache[k, V]) Start(d time.Duration) {
-	tc.ticker = time.NewTicker(d)
-	for range tc.ticker.C {
-		if tc.Job != nil {
-			tc.Job()
+// reset empties the cache, sized for n items, and returns its old entries.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) reset(n int) map[K]*entry[K, V] {
+	old := c.items
+	c.items = make(map[K]*entry[K, V], n)
+	c.order = make([]*entry[K, V], 0, n)
+	c.count.Store(0)
+	c.cost = 0
+	c.recipes = nil
+	c.tierKeys = nil
+	for _, idx := range c.indexes {
+		idx.keys = make(map[string]map[K]struct{})
+	}
+	if c.lru != nil {
+		c.lru.init()
+	}
+	return old
+}
+
+// remove deletes the entry mapped to key, along with any recipe to recompute
+// it, and returns it. The caller must hold the write lock.
+func (c *Cache[K, V]) remove(key K) (*entry[K, V], bool) {
+	e, ok := c.unlink(key)
+	delete(c.recipes, key)
+	return e, ok
+}
+
+// unlink deletes the entry mapped to key and returns it, keeping any recipe
+// to recompute it for when key is next read. The caller must hold the write
+// lock.
+func (c *Cache[K, V]) unlink(key K) (*entry[K, V], bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if e.recipe != nil {
+		if c.recipes == nil {
+			c.recipes = make(map[K]recipe[V])
+		}
+		c.recipes[key] = recipe[V]{e.recipe, e.item.ExpiredAt}
+	}
+	delete(c.items, key)
+	last := c.order[len(c.order)-1]
+	c.order[e.pos], last.pos = last, e.pos
+	c.order[len(c.order)-1] = nil
+	c.order = c.order[:len(c.order)-1]
+	c.count.Add(-1)
+	c.cost -= e.cost
+	c.unindex(e)
+	c.untier(key, e)
+	if c.lru != nil {
+		c.lru.remove(e)
+	}
+	return e, true
+}
+
+// read retrieves the item mapped to key and records the access. The caller
+// must hold the read or the write lock.
+func (c *Cache[K, V]) read(key K) (Item[V], bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return Item[V]{}, false
+	}
+	e.accessed.Store(c.clock.Now().UnixNano())
+	c.touch(e)
+	return e.item, true
+}
+
+// touch marks e as the most recently used entry. It is safe to call while
+// holding either the read or the write lock.
+func (c *Cache[K, V]) touch(e *entry[K, V]) {
+	if c.lru == nil {
+		return
+	}
+	c.lruMu.Lock()
+	c.lru.moveToFront(e)
+	c.lruMu.Unlock()
+}
+
+// notify calls the OnEvict callback for each eviction with a background
+// context. It must be called without holding the lock.
+func (c *Cache[K, V]) notify(evicted []eviction[K, V]) {
+	c.notifyContext(context.Background(), evicted)
+}
+
+// evictAll calls the OnEvict callback for every item left in the cache with
+// EvictCleared. It is the finalizer set by WithFinalizer.
+func (c *Cache[K, V]) evictAll() {
+	if c.onEvict == nil {
+		return
+	}
+	for key, e := range c.items {
+		c.onEvict(context.Background(), key, e.item.Value, EvictCleared)
+	}
+}
+
+// notifyContext calls the OnEvict callback for each eviction with ctx, logs
+// the evictions, and publishes them to subscribers. It must be called without
+// holding the lock.
+func (c *Cache[K, V]) notifyContext(ctx context.Context, evicted []eviction[K, V]) {
+	if c.onEvict != nil {
+		for _, ev := range evicted {
+			c.onEvict(ctx, ev.key, ev.item.Value, ev.reason)
 		}
 	}
+	c.logEvictions(ctx, evicted)
+	c.publish(evicted)
 }
 
-// Stop immediately stops ticking to prevent Job from being called.
-func (tc *TickingCache[K, V]) Stop() {
-	if tc.ticker != nil {
-		tc.ticker.Stop()
+// initZero prepares a zero Cache for use with the defaults NewCache gives
+// it. It does nothing to a cache made by NewCache.
+func (c *Cache[K, V]) initZero() {
+	c.lock()
+	defer c.mu.Unlock()
+	if c.items != nil {
+		return
 	}
+	c.items = make(map[K]*entry[K, V])
+	c.id = cacheIDs.Add(1)
+	c.clock = utcClock{}
+	c.group = &Group[K, V]{}
 }
 
-// NewTickingCache creates a Cache with K type keys and V type values and starts
-// a single, new go routine that calls job at every tick denoted by duration.
-func NewTickingCache[K comparable, V any](d time.Duration) *TickingCache[K, V] {
-	tc := &TickingCache[K, V]{Cache: NewCache[K, V]()}
-	go tc.Start(d)
-	return tc
+// NewCache creates a Cache with K type keys and V type values configured by
+// opts.
+func NewCache[K comparable, V any](opts ...Option[K, V]) *Cache[K, V] {
+	c := &Cache[K, V]{
+		items: make(map[K]*entry[K, V]),
+		id:    cacheIDs.Add(1),
+		clock: utcClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.group == nil {
+		c.group = &Group[K, V]{}
+	}
+	if c.capacity > 0 || c.maxCost > 0 {
+		c.lru = newLRUList[K, V]()
+	}
+	if c.finalize {
+		runtime.SetFinalizer(c, (*Cache[K, V]).evictAll)
+	}
+	return c
 }
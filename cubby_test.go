@@ -1,9 +1,17 @@
 package cubby
 
 import (
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/novrin/cubby/cubbytest"
 )
 
 const errorString = "\nGot:\t%v\nWant:\t%v\n"
@@ -45,6 +53,29 @@ func TestIsExpired(t *testing.T) {
 	}
 }
 
+func TestPauseExpiration(t *testing.T) {
+	item := Item[int]{Value: 1, CreatedAt: past, ExpiredAt: past}
+	cache := NewCache[string, int]()
+	cache.SetItem("x", item)
+	PauseExpiration()
+	if item.IsExpired() {
+		ResumeExpiration()
+		t.Fatalf("Wanted item not to expire while expiration is paused")
+	}
+	cache.ClearExpired()
+	ResumeExpiration()
+	if cache.Len() != 1 {
+		t.Fatalf(errorString, cache.Len(), 1)
+	}
+	if !item.IsExpired() {
+		t.Fatalf("Wanted item to expire after expiration is resumed")
+	}
+	cache.ClearExpired()
+	if cache.Len() != 0 {
+		t.Fatalf(errorString, cache.Len(), 0)
+	}
+}
+
 var keys = []string{"x", "y", "z"}
 
 func TestCache(t *testing.T) {
@@ -74,6 +105,118 @@ func TestSetItem(t *testing.T) {
 	}
 }
 
+func TestUpdateAll(t *testing.T) {
+	cases := map[string]struct {
+		fn   func(string, int) (int, bool)
+		n    int
+		want map[string]int
+	}{
+		"all": {
+			fn:   func(_ string, v int) (int, bool) { return v * 10, true },
+			n:    2,
+			want: map[string]int{"x": 10, "y": 20},
+		},
+		"some": {
+			fn:   func(k string, v int) (int, bool) { return v * 10, k == "y" },
+			n:    1,
+			want: map[string]int{"x": 1, "y": 20},
+		},
+		"none": {
+			fn:   func(_ string, v int) (int, bool) { return 0, false },
+			want: map[string]int{"x": 1, "y": 2},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			cache.Set("x", 1)
+			cache.Set("y", 2)
+			cache.SetItem("z", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+			if n := cache.UpdateAll(c.fn); n != c.n {
+				t.Fatalf(errorString, n, c.n)
+			}
+			if got := cache.ToMap(); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if z, _ := cache.GetItem("z"); z.Value != 3 {
+				t.Fatalf(errorString, z.Value, 3)
+			}
+		})
+	}
+}
+
+func TestZeroCreatedAt(t *testing.T) {
+	cases := map[string]func(cache *Cache[string, int], item Item[int]){
+		"SetItem": func(cache *Cache[string, int], item Item[int]) {
+			cache.SetItem("x", item)
+		},
+		"SwapAll": func(cache *Cache[string, int], item Item[int]) {
+			cache.SwapAll(map[string]Item[int]{"x": item})
+		},
+		"Load": func(cache *Cache[string, int], item Item[int]) {
+			data, err := cache.encodeSnapshot(map[string]Item[int]{"x": item})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cache.Load(bytes.NewReader(data)); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	for name, write := range cases {
+		for _, c := range []struct{ created, want time.Time }{
+			{created: time.Time{}, want: now},
+			{created: past, want: past},
+		} {
+			cache := NewCache(WithClock[string, int](cubbytest.NewFakeClock(now)))
+			write(cache, Item[int]{Value: 1, CreatedAt: c.created})
+			if item, _ := cache.GetItem("x"); !item.CreatedAt.Equal(c.want) {
+				t.Fatalf("%s:"+errorString, name, item.CreatedAt, c.want)
+			}
+		}
+	}
+}
+
+func TestSetItemChecked(t *testing.T) {
+	cases := map[string]struct {
+		item Item[int]
+		want error
+	}{
+		"no expiration": {
+			item: Item[int]{Value: 1, CreatedAt: now},
+			want: nil,
+		},
+		"expires after creation": {
+			item: Item[int]{Value: 2, CreatedAt: now, ExpiredAt: future},
+			want: nil,
+		},
+		"expires at creation": {
+			item: Item[int]{Value: 3, CreatedAt: now, ExpiredAt: now},
+			want: nil,
+		},
+		"expires before creation": {
+			item: Item[int]{Value: 4, CreatedAt: now, ExpiredAt: past},
+			want: ErrInvalidItem,
+		},
+		"zero creation expires before now": {
+			item: Item[int]{Value: 5, ExpiredAt: past},
+			want: ErrInvalidItem,
+		},
+	}
+	cache := NewCache(WithClock[string, int](cubbytest.NewFakeClock(now)))
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := cache.SetItemChecked(name, c.item)
+			if !errors.Is(err, c.want) {
+				t.Fatalf(errorString, err, c.want)
+			}
+			if _, ok := cache.GetItem(name); ok != (c.want == nil) {
+				t.Fatalf(errorString, ok, c.want == nil)
+			}
+		})
+	}
+}
+
 func TestSet(t *testing.T) {
 	cases := map[string][]int{
 		"initial": {1, 2, 3},
@@ -116,6 +259,220 @@ func TestSetToExpire(t *testing.T) {
 	}
 }
 
+func TestLastAccessed(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now.UTC())
+	cache := NewCache(WithClock[string, int](clock))
+	if _, ok := cache.LastAccessed("x"); ok {
+		t.Fatalf("Got last access for x but x should not exist.")
+	}
+	cache.Set("x", 1)
+	if got, _ := cache.LastAccessed("x"); !got.Equal(clock.Now()) {
+		t.Fatalf(errorString, got, clock.Now())
+	}
+	clock.Advance(1 * time.Minute)
+	cache.Get("x")
+	clock.Advance(1 * time.Minute)
+	want := now.Add(1 * time.Minute)
+	if got, _ := cache.LastAccessed("x"); !got.Equal(want) {
+		t.Fatalf(errorString, got, want)
+	}
+}
+
+func TestSetToExpireIfLonger(t *testing.T) {
+	cases := map[string]struct {
+		existing   *Item[int]
+		lifetime   time.Duration
+		want       bool
+		wantExpiry time.Time
+	}{
+		"missing":       {lifetime: time.Minute, want: true, wantExpiry: now.Add(time.Minute)},
+		"longer":        {existing: &Item[int]{Value: 1, CreatedAt: now, ExpiredAt: now.Add(time.Minute)}, lifetime: time.Hour, want: true, wantExpiry: now.Add(time.Hour)},
+		"shorter":       {existing: &Item[int]{Value: 1, CreatedAt: now, ExpiredAt: now.Add(time.Hour)}, lifetime: time.Minute, want: false, wantExpiry: now.Add(time.Hour)},
+		"equal":         {existing: &Item[int]{Value: 1, CreatedAt: now, ExpiredAt: now.Add(time.Hour)}, lifetime: time.Hour, want: false, wantExpiry: now.Add(time.Hour)},
+		"expired":       {existing: &Item[int]{Value: 1, CreatedAt: past, ExpiredAt: past}, lifetime: time.Minute, want: true, wantExpiry: now.Add(time.Minute)},
+		"never expires": {existing: &Item[int]{Value: 1, CreatedAt: now}, lifetime: time.Hour, want: false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(WithClock[string, int](cubbytest.NewFakeClock(now)))
+			if c.existing != nil {
+				cache.SetItem("x", *c.existing)
+			}
+			if got := cache.SetToExpireIfLonger("x", 2, c.lifetime); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			item, _ := cache.GetItem("x")
+			if item.Value != 2 {
+				t.Fatalf(errorString, item.Value, 2)
+			}
+			if !item.ExpiredAt.Equal(c.wantExpiry) {
+				t.Fatalf(errorString, item.ExpiredAt, c.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestGetOrSetToExpire(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	cache.SetToExpire("live", 1, 1*time.Hour)
+	cache.SetToExpire("expired", 2, 1*time.Second)
+	clock.Advance(1 * time.Minute)
+	cases := map[string]struct {
+		want      int
+		loaded    bool
+		expiredAt time.Time
+	}{
+		"live":    {want: 1, loaded: true, expiredAt: now.Add(1 * time.Hour)},
+		"expired": {want: 7, loaded: false, expiredAt: clock.Now().Add(1 * time.Minute)},
+		"missing": {want: 7, loaded: false, expiredAt: clock.Now().Add(1 * time.Minute)},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, loaded := cache.GetOrSetToExpire(name, 7, 1*time.Minute)
+			if got != c.want || loaded != c.loaded {
+				t.Fatalf(errorString, got, c.want)
+			}
+			item, _ := cache.GetItem(name)
+			if item.ExpiredAt != c.expiredAt {
+				t.Fatalf(errorString, item.ExpiredAt, c.expiredAt)
+			}
+		})
+	}
+}
+
+func TestLoadOrStore(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock), WithTTL[string, int](1*time.Hour))
+	cache.Set("live", 1)
+	cache.SetToExpire("expired", 2, 1*time.Second)
+	clock.Advance(1 * time.Minute)
+	cases := map[string]struct {
+		want      int
+		loaded    bool
+		expiredAt time.Time
+	}{
+		"live":    {want: 1, loaded: true, expiredAt: now.Add(1 * time.Hour)},
+		"expired": {want: 7, loaded: false, expiredAt: clock.Now().Add(1 * time.Hour)},
+		"missing": {want: 7, loaded: false, expiredAt: clock.Now().Add(1 * time.Hour)},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, loaded := cache.LoadOrStore(name, 7)
+			if got != c.want || loaded != c.loaded {
+				t.Fatalf(errorString, got, c.want)
+			}
+			item, _ := cache.GetItem(name)
+			if item.ExpiredAt != c.expiredAt {
+				t.Fatalf(errorString, item.ExpiredAt, c.expiredAt)
+			}
+		})
+	}
+}
+
+func TestGetAndTouch(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	cache.SetToExpire("x", 1, 1*time.Minute)
+	cache.SetToExpire("y", 2, 1*time.Second)
+	clock.Advance(30 * time.Second)
+	if v, ok := cache.GetAndTouch("x", 1*time.Minute); !ok || v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+	item, _ := cache.GetItem("x")
+	if want := clock.Now().Add(1 * time.Minute); item.ExpiredAt != want {
+		t.Fatalf(errorString, item.ExpiredAt, want)
+	}
+	for _, k := range []string{"y", "z"} {
+		if _, ok := cache.GetAndTouch(k, 1*time.Minute); ok {
+			t.Fatalf("Got value for %s but %s should be expired or missing.", k, k)
+		}
+	}
+}
+
+func TestGetOrZero(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.SetItem("y", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+	cases := map[string]struct {
+		key  string
+		want int
+	}{
+		"present": {key: "x", want: 1},
+		"expired": {key: "y", want: 0},
+		"missing": {key: "z", want: 0},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := cache.GetOrZero(c.key); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGetItemCopy(t *testing.T) {
+	cache := NewCache[string, []int]()
+	cache.Set("x", []int{1, 2, 3})
+	item, ok := cache.GetItemCopy("x", slices.Clone[[]int])
+	if !ok {
+		t.Fatalf("Wanted key x to be in cache but it was not")
+	}
+	item.Value[0] = 100
+	if got, _ := cache.Get("x"); got[0] != 1 {
+		t.Fatalf(errorString, got, []int{1, 2, 3})
+	}
+	if _, ok := cache.GetItemCopy("missing", slices.Clone[[]int]); ok {
+		t.Fatalf("Got missing key but wanted it to be missing")
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("fresh", 1)
+	cache.SetItem("stale", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+	cases := map[string]struct {
+		want      int
+		stale, ok bool
+	}{
+		"fresh":   {want: 1, stale: false, ok: true},
+		"stale":   {want: 2, stale: true, ok: true},
+		"missing": {want: 0, stale: false, ok: false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, stale, ok := cache.GetStale(name)
+			if v != c.want || stale != c.stale || ok != c.ok {
+				t.Fatalf(errorString, []any{v, stale, ok}, []any{c.want, c.stale, c.ok})
+			}
+		})
+	}
+	if v, ok := cache.Get("stale"); !ok || v != 2 {
+		t.Fatalf(errorString, v, 2)
+	}
+}
+
+func TestSetToExpireNonPositive(t *testing.T) {
+	cases := map[string]time.Duration{
+		"zero":     0,
+		"negative": -1 * time.Minute,
+	}
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	for name, lifetime := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache.SetToExpire(name, 1, lifetime)
+			item, _ := cache.GetItem(name)
+			if !item.expiredAt(clock.Now()) {
+				t.Fatalf("Wanted item %s to be expired immediately", name)
+			}
+			if !item.IsExpired() {
+				t.Fatalf("Wanted item %s to report IsExpired", name)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	cache := NewCache[string, int]()
 	values := []int{1, 2, 3}
@@ -131,6 +488,60 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestDeleteIf(t *testing.T) {
+	owner := func(want string) func(string) bool {
+		return func(v string) bool { return v == want }
+	}
+	cases := map[string]struct {
+		key     string
+		pred    func(string) bool
+		want    bool
+		wantLen int
+	}{
+		"match":    {key: "lock", pred: owner("a"), want: true, wantLen: 0},
+		"no match": {key: "lock", pred: owner("b"), want: false, wantLen: 1},
+		"missing":  {key: "other", pred: owner("a"), want: false, wantLen: 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var evicted []EvictReason
+			cache := NewCache(WithOnEvict(func(_ string, _ string, reason EvictReason) {
+				evicted = append(evicted, reason)
+			}))
+			cache.Set("lock", "a")
+			if got := cache.DeleteIf(c.key, c.pred); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if cache.Len() != c.wantLen {
+				t.Fatalf(errorString, cache.Len(), c.wantLen)
+			}
+			if c.want && (len(evicted) != 1 || evicted[0] != EvictDeleted) {
+				t.Fatalf(errorString, evicted, []EvictReason{EvictDeleted})
+			}
+		})
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	var evicted []string
+	cache := NewCache(WithOnEvict(func(key string, value int, reason EvictReason) {
+		evicted = append(evicted, key)
+	}))
+	values := []int{1, 2, 3}
+	for i, k := range keys {
+		cache.Set(k, values[i])
+	}
+	if n := cache.DeleteMany([]string{"x", "z", "w"}); n != 2 {
+		t.Fatalf(errorString, n, 2)
+	}
+	if cache.Len() != 1 {
+		t.Fatalf(errorString, cache.Len(), 1)
+	}
+	if len(evicted) != 2 {
+		t.Fatalf(errorString, len(evicted), 2)
+	}
+}
+
 func TestClear(t *testing.T) {
 	cache := NewCache[string, int]()
 	values := []int{1, 2, 3}
@@ -146,6 +557,49 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestClearExcept(t *testing.T) {
+	var evicted []string
+	cache := NewCache(WithOnEvict(func(key string, _ int, reason EvictReason) {
+		evicted = append(evicted, key+" "+reason.String())
+	}))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	n := cache.ClearExcept(func(key string, item Item[int]) bool {
+		return item.Value%2 == 1
+	})
+	if n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+	if got, want := cache.ToMap(), map[string]int{"a": 1, "c": 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+	if want := []string{"b cleared"}; !reflect.DeepEqual(evicted, want) {
+		t.Fatalf(errorString, evicted, want)
+	}
+}
+
+func TestSwapAll(t *testing.T) {
+	cache := NewCache(WithCapacity[string, int](2))
+	cache.Set("x", 1)
+	cache.Set("y", 2)
+	old := cache.SwapAll(map[string]Item[int]{
+		"y": {Value: 3, CreatedAt: now},
+		"z": {Value: 4, CreatedAt: now},
+	})
+	if len(old) != 2 || old["x"].Value != 1 || old["y"].Value != 2 {
+		t.Fatalf(errorString, old, "items x and y")
+	}
+	got := cache.ToMap()
+	if want := map[string]int{"y": 3, "z": 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+	cache.Set("w", 5) // the cache is still bounded
+	if cache.Len() != 2 {
+		t.Fatalf(errorString, cache.Len(), 2)
+	}
+}
+
 func TestClearExpired(t *testing.T) {
 	cases := map[string]struct {
 		items map[string]Item[int]
@@ -194,6 +648,87 @@ func TestClearExpired(t *testing.T) {
 	}
 }
 
+// countingClock is a Clock that counts how often it is read.
+type countingClock struct {
+	*cubbytest.FakeClock
+	reads atomic.Int32
+}
+
+func (c *countingClock) Now() time.Time {
+	c.reads.Add(1)
+	return c.FakeClock.Now()
+}
+
+func TestClearExpiredReadsClockOnce(t *testing.T) {
+	clock := &countingClock{FakeClock: cubbytest.NewFakeClock(now)}
+	cache := NewCache(
+		WithClock[string, int](clock),
+		WithOnEvict(func(string, int, EvictReason) {}),
+		WithOnSweep[string, int](func(map[string]Item[int]) {}),
+	)
+	for i := 0; i < 100; i++ {
+		cache.SetItem(strconv.Itoa(i), Item[int]{Value: i, CreatedAt: now, ExpiredAt: now.Add(time.Duration(i) * time.Second)})
+	}
+	clock.Advance(50 * time.Second)
+	clock.reads.Store(0)
+	cache.ClearExpired()
+	if got := clock.reads.Load(); got != 1 {
+		t.Fatalf(errorString, got, 1)
+	}
+	if cache.Len() != 49 { // items 0 through 50 expired
+		t.Fatalf(errorString, cache.Len(), 49)
+	}
+}
+
+func TestClearExpiredN(t *testing.T) {
+	cases := map[string]struct {
+		limit int
+		want  []int // removed by each call
+	}{
+		"in batches":    {limit: 2, want: []int{2, 2, 1, 0}},
+		"limit exceeds": {limit: 10, want: []int{5, 0}},
+		"exact":         {limit: 5, want: []int{5, 0}},
+		"zero":          {limit: 0, want: []int{0}},
+		"negative":      {limit: -1, want: []int{0}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			cache.Set("live", 0)
+			for i := 0; i < 5; i++ {
+				cache.SetItem(strconv.Itoa(i), Item[int]{Value: i, CreatedAt: past, ExpiredAt: past})
+			}
+			total := 0
+			for _, want := range c.want {
+				n := cache.ClearExpiredN(c.limit)
+				if n != want {
+					t.Fatalf(errorString, n, want)
+				}
+				total += n
+			}
+			if cache.Len() != 6-total {
+				t.Fatalf(errorString, cache.Len(), 6-total)
+			}
+		})
+	}
+}
+
+func TestExpiredKeys(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("noEx1", 1)
+	cache.SetItem("noEx2", Item[int]{Value: 2, CreatedAt: now, ExpiredAt: future})
+	cache.SetItem("ex1", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+	cache.SetItem("ex2", Item[int]{Value: 4, CreatedAt: past, ExpiredAt: past})
+	got := cache.ExpiredKeys()
+	sort.Strings(got)
+	if want := []string{"ex1", "ex2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+	if cache.Len() != 4 {
+		t.Fatalf(errorString, cache.Len(), 4)
+	}
+}
+
 func TestItems(t *testing.T) {
 	cases := map[string]struct {
 		items map[string]Item[int]
@@ -243,40 +778,17 @@ func TestItems(t *testing.T) {
 	}
 }
 
-func TestTickingCache(t *testing.T) {
-	cache := NewTickingCache[string, int](1 * time.Minute)
-	for _, k := range keys {
-		if _, ok := cache.Get(k); ok {
-			t.Fatalf("Got value for %s but %s should not exist.", k, k)
-		}
-	}
-}
-
-func TestTickingCacheStartAndStop(t *testing.T) {
-	cache := NewTickingCache[string, int](5 * time.Millisecond)
-	cache.Job = func() {
-		cache.ClearExpired()
-	}
-	values := []int{1, 2, 3}
-	for i, k := range keys {
-		cache.SetToExpire(k, values[i], 1*time.Millisecond)
-	}
-	if cache.Len() != len(values) {
-		t.Fatalf("Got cache length %v but wanted %v", cache.Len(), len(values))
-	}
-	time.Sleep(10 * time.Millisecond)
-	if cache.Len() != 0 {
-		t.Fatalf("Got %v items but wanted cache to be empty", cache.Len())
-	}
-	for i, k := range keys {
-		cache.SetToExpire(k, values[i], 1*time.Millisecond)
-	}
-	if cache.Len() != len(values) {
-		t.Fatalf("Got cache length %v but wanted %v", cache.Len(), len(values))
+func TestToMap(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.SetItem("y", Item[int]{Value: 2, CreatedAt: now, ExpiredAt: future})
+	cache.SetItem("z", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+	got := cache.ToMap()
+	if want := map[string]int{"x": 1, "y": 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
 	}
-	cache.Stop()
-	time.Sleep(10 * time.Millisecond)
-	if cache.Len() == 0 { // ticker did not stop and items were cleared
-		t.Fatalf("Got empty cache but wanted to have %v items", cache.Len())
+	got["x"] = 7
+	if v, _ := cache.Get("x"); v != 1 {
+		t.Fatalf(errorString, v, 1)
 	}
 }
@@ -0,0 +1,42 @@
+// Package cubbytest provides test doubles for code that uses cubby caches.
+package cubbytest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a cubby.Clock that reports a fixed time until it is advanced
+// or set, so tests can drive expiration deterministically. It is safe for
+// concurrent use. Its zero value reports the zero time.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock that reports t.
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now returns the time the clock reports.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the time the clock reports forward by d, or back if d is
+// negative.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set makes the clock report t.
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+}
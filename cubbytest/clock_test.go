@@ -0,0 +1,50 @@
+package cubbytest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby"
+	"github.com/novrin/cubby/cubbytest"
+)
+
+const errorString = "\nGot:\t%v\nWant:\t%v\n"
+
+var _ cubby.Clock = (*cubbytest.FakeClock)(nil)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := cubbytest.NewFakeClock(start)
+	steps := []struct {
+		name string
+		do   func()
+		want time.Time
+	}{
+		{name: "new", do: func() {}, want: start},
+		{name: "advance", do: func() { clock.Advance(time.Hour) }, want: start.Add(time.Hour)},
+		{name: "advance back", do: func() { clock.Advance(-time.Minute) }, want: start.Add(59 * time.Minute)},
+		{name: "set", do: func() { clock.Set(start) }, want: start},
+	}
+	for _, s := range steps {
+		s.do()
+		if got := clock.Now(); !got.Equal(s.want) {
+			t.Fatalf("%s:"+errorString, s.name, got, s.want)
+		}
+	}
+}
+
+func TestFakeClockDrivesExpiration(t *testing.T) {
+	clock := cubbytest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := cubby.NewCache(
+		cubby.WithClock[string, int](clock),
+		cubby.WithLazyExpiration[string, int](),
+	)
+	cache.SetToExpire("x", 1, time.Minute)
+	if _, ok := cache.Get("x"); !ok {
+		t.Fatalf("Wanted x before it expired but it was missing")
+	}
+	clock.Advance(time.Minute)
+	if _, ok := cache.Get("x"); ok {
+		t.Fatalf("Got x after it expired but wanted it missing")
+	}
+}
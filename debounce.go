@@ -0,0 +1,63 @@
+package cubby
+
+import "time"
+
+// debounced is a value waiting to be stored by SetDebounced.
+type debounced[V any] struct {
+	value V
+	timer *time.Timer
+}
+
+// SetDebounced sets value for key, as Set does, once window elapses without
+// another SetDebounced of key. A burst of updates to key is thereby coalesced
+// into one store of the last value, reducing write churn and OnEvict
+// notifications. Until it is stored, the value is not visible to Get.
+//
+// Pending values are stored at once by Flush, by Clear before it clears the
+// cache, and by Stop on a TickingCache.
+func (c *Cache[K, V]) SetDebounced(key K, value V, window time.Duration) {
+	c.debounceMu.Lock()
+	defer c.debounceMu.Unlock()
+	if d, ok := c.debounced[key]; ok && d.timer.Stop() {
+		d.value = value
+		d.timer.Reset(window)
+		return
+	}
+	// Any timer that already fired finds itself replaced and stores nothing.
+	d := &debounced[V]{value: value}
+	d.timer = time.AfterFunc(window, func() {
+		c.storeDebounced(key, d)
+	})
+	if c.debounced == nil {
+		c.debounced = make(map[K]*debounced[V])
+	}
+	c.debounced[key] = d
+}
+
+// Flush stores every value pending from SetDebounced immediately.
+func (c *Cache[K, V]) Flush() {
+	c.debounceMu.Lock()
+	pending := c.debounced
+	c.debounced = nil
+	for _, d := range pending {
+		d.timer.Stop()
+	}
+	c.debounceMu.Unlock()
+	for key, d := range pending {
+		c.Set(key, d.value)
+	}
+}
+
+// storeDebounced stores the value of d for key unless it has since been
+// replaced or flushed.
+func (c *Cache[K, V]) storeDebounced(key K, d *debounced[V]) {
+	c.debounceMu.Lock()
+	if c.debounced[key] != d {
+		c.debounceMu.Unlock()
+		return
+	}
+	delete(c.debounced, key)
+	value := d.value
+	c.debounceMu.Unlock()
+	c.Set(key, value)
+}
@@ -0,0 +1,69 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetDebounced(t *testing.T) {
+	cache := NewCache[string, int]()
+	for i := 1; i <= 5; i++ {
+		cache.SetDebounced("x", i, 20*time.Millisecond)
+		time.Sleep(2 * time.Millisecond)
+	}
+	cache.SetDebounced("y", 1, 20*time.Millisecond)
+	if _, ok := cache.Get("x"); ok {
+		t.Fatalf("Wanted x to be pending but it was stored")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if v, _ := cache.Get("x"); v != 5 {
+		t.Fatalf(errorString, v, 5)
+	}
+	if v, _ := cache.Get("y"); v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	cases := map[string]struct {
+		flush func(*TickingCache[string, int])
+		want  map[string]EvictReason
+		len   int
+	}{
+		"flush": {flush: func(c *TickingCache[string, int]) { c.Flush() }, want: map[string]EvictReason{}, len: 2},
+		"clear": {
+			flush: func(c *TickingCache[string, int]) { c.Clear() },
+			want:  map[string]EvictReason{"x": EvictCleared, "y": EvictCleared},
+		},
+		"stop": {flush: func(c *TickingCache[string, int]) { c.Stop() }, want: map[string]EvictReason{}, len: 2},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := map[string]EvictReason{}
+			cache := NewTickingCache(1*time.Hour, WithOnEvict(func(key string, _ int, reason EvictReason) {
+				got[key] = reason
+			}))
+			defer cache.Stop()
+			cache.SetDebounced("x", 1, 1*time.Hour)
+			cache.SetDebounced("y", 2, 1*time.Hour)
+			cache.SetDebounced("x", 3, 1*time.Hour)
+			c.flush(cache)
+			if cache.Len() != c.len {
+				t.Fatalf(errorString, cache.Len(), c.len)
+			}
+			if c.len > 0 {
+				if v, _ := cache.Get("x"); v != 3 {
+					t.Fatalf(errorString, v, 3)
+				}
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+			for k, reason := range c.want {
+				if got[k] != reason {
+					t.Fatalf(errorString, got[k], reason)
+				}
+			}
+		})
+	}
+}
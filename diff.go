@@ -0,0 +1,31 @@
+package cubby
+
+// Diff compares the values in caches c and other. It returns the keys only in
+// c as added, the keys only in other as removed, and the keys in both whose
+// values differ as changed. Use DiffFunc for values that are not comparable.
+func Diff[K comparable, V comparable](c, other *Cache[K, V]) (added, removed, changed []K) {
+	return c.DiffFunc(other, func(a, b V) bool {
+		return a == b
+	})
+}
+
+// DiffFunc is like Diff but reports values as equal using the equal
+// function. Each cache is read from a snapshot taken under its own lock.
+func (c *Cache[K, V]) DiffFunc(other *Cache[K, V], equal func(a, b V) bool) (added, removed, changed []K) {
+	mine, theirs := c.Items(), other.Items()
+	for k, item := range mine {
+		o, ok := theirs[k]
+		switch {
+		case !ok:
+			added = append(added, k)
+		case !equal(item.Value, o.Value):
+			changed = append(changed, k)
+		}
+	}
+	for k := range theirs {
+		if _, ok := mine[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	return added, removed, changed
+}
@@ -0,0 +1,51 @@
+package cubby
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := NewCache[string, int]()
+	b := NewCache[string, int]()
+	a.Set("same", 1)
+	b.Set("same", 1)
+	a.Set("changed", 2)
+	b.Set("changed", 3)
+	a.Set("added1", 4)
+	a.Set("added2", 5)
+	b.Set("removed", 6)
+	added, removed, changed := Diff(a, b)
+	slices.Sort(added)
+	cases := map[string]struct {
+		got, want []string
+	}{
+		"added":   {got: added, want: []string{"added1", "added2"}},
+		"removed": {got: removed, want: []string{"removed"}},
+		"changed": {got: changed, want: []string{"changed"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if !reflect.DeepEqual(c.got, c.want) {
+				t.Fatalf(errorString, c.got, c.want)
+			}
+		})
+	}
+}
+
+func TestDiffFunc(t *testing.T) {
+	a := NewCache[string, []int]()
+	b := NewCache[string, []int]()
+	a.Set("same", []int{1, 2})
+	b.Set("same", []int{1, 2})
+	a.Set("changed", []int{1})
+	b.Set("changed", []int{2})
+	added, removed, changed := a.DiffFunc(b, slices.Equal[[]int])
+	if len(added) != 0 || len(removed) != 0 {
+		t.Fatalf(errorString, append(added, removed...), []string{})
+	}
+	if want := []string{"changed"}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf(errorString, changed, want)
+	}
+}
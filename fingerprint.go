@@ -0,0 +1,44 @@
+package cubby
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+)
+
+// Fingerprint returns a hash of the keys, values, and expiration dates of the
+// unexpired items in the cache. It changes when they change, so comparing it
+// with a fingerprint taken earlier tells cheaply whether the cache needs to
+// be saved again. It does not depend on the order of items and is the same
+// across processes for the same contents.
+//
+// Keys, and values unless the cache was created WithHasher, are hashed by
+// their default formatting, as with fmt's %v verb. That suits strings,
+// numbers, and structs of them, but not values whose formatting hides their
+// contents, such as pointers; give those a hasher. Distinct contents may
+// share a fingerprint, though rarely.
+func (c *Cache[K, V]) Fingerprint() uint64 {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	h := fnv.New64a()
+	var buf [8]byte
+	var sum uint64
+	for k, e := range c.items {
+		if e.item.expiredAt(now) {
+			continue
+		}
+		h.Reset()
+		_, _ = fmt.Fprintf(h, "%v\x00", k)
+		if c.hasher != nil {
+			binary.LittleEndian.PutUint64(buf[:], c.hasher(e.item.Value))
+			_, _ = h.Write(buf[:])
+		} else {
+			_, _ = fmt.Fprintf(h, "%v", e.item.Value)
+		}
+		binary.LittleEndian.PutUint64(buf[:], uint64(unixNano(e.item.ExpiredAt)))
+		_, _ = h.Write(buf[:])
+		sum += h.Sum64() // addition is independent of iteration order
+	}
+	return sum
+}
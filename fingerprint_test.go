@@ -0,0 +1,55 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := func() *Cache[string, int] {
+		cache := NewCache[string, int]()
+		cache.SetItem("a", Item[int]{Value: 1, CreatedAt: now})
+		cache.SetItem("b", Item[int]{Value: 2, CreatedAt: now, ExpiredAt: future})
+		return cache
+	}
+	want := base().Fingerprint()
+	cases := map[string]struct {
+		change func(*Cache[string, int])
+		same   bool
+	}{
+		"unchanged":     {change: func(*Cache[string, int]) {}, same: true},
+		"touched":       {change: func(c *Cache[string, int]) { c.Get("a") }, same: true},
+		"expired item":  {change: func(c *Cache[string, int]) { c.SetItem("c", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past}) }, same: true},
+		"value changed": {change: func(c *Cache[string, int]) { c.SetItem("a", Item[int]{Value: 5, CreatedAt: now}) }, same: false},
+		"expiry changed": {change: func(c *Cache[string, int]) {
+			c.SetItem("b", Item[int]{Value: 2, CreatedAt: now, ExpiredAt: future.Add(time.Second)})
+		}, same: false},
+		"key added":   {change: func(c *Cache[string, int]) { c.Set("c", 3) }, same: false},
+		"key deleted": {change: func(c *Cache[string, int]) { c.Delete("a") }, same: false},
+		"values swapped": {change: func(c *Cache[string, int]) {
+			c.SetItem("a", Item[int]{Value: 2, CreatedAt: now})
+			c.SetItem("b", Item[int]{Value: 1, CreatedAt: now, ExpiredAt: future})
+		}, same: false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := base()
+			c.change(cache)
+			if got := cache.Fingerprint() == want; got != c.same {
+				t.Fatalf(errorString, got, c.same)
+			}
+		})
+	}
+}
+
+func TestFingerprintWithHasher(t *testing.T) {
+	hash := func(p *int) uint64 { return uint64(*p) }
+	a, b := 1, 1
+	one := NewCache(WithHasher[string, *int](hash))
+	one.Set("x", &a)
+	other := NewCache(WithHasher[string, *int](hash))
+	other.Set("x", &b)
+	if one.Fingerprint() != other.Fingerprint() {
+		t.Fatalf("Got different fingerprints for pointers to equal values")
+	}
+}
@@ -0,0 +1,65 @@
+package cubby
+
+// Frozen is an immutable, read-optimized copy of the unexpired items of a
+// Cache, made by Freeze. It holds only keys and values, stored contiguously,
+// and needs no lock, so it is safe for concurrent reads. It has no methods
+// that write.
+type Frozen[K comparable, V any] struct {
+	index  map[K]int // positions of keys in keys and values
+	keys   []K
+	values []V
+}
+
+// Freeze returns a Frozen copy of the unexpired items in the cache, suited to
+// caches that are filled once and then only read, such as configuration. The
+// copy is not affected by later changes to the cache, and its items never
+// expire.
+func (c *Cache[K, V]) Freeze() *Frozen[K, V] {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	f := &Frozen[K, V]{
+		index:  make(map[K]int, len(c.items)),
+		keys:   make([]K, 0, len(c.items)),
+		values: make([]V, 0, len(c.items)),
+	}
+	for k, e := range c.items {
+		if e.item.expiredAt(now) {
+			continue
+		}
+		f.index[k] = len(f.keys)
+		f.keys = append(f.keys, k)
+		f.values = append(f.values, e.item.Value)
+	}
+	return f
+}
+
+// Get retrieves the value mapped to key.
+func (f *Frozen[K, V]) Get(key K) (V, bool) {
+	i, ok := f.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return f.values[i], true
+}
+
+// Has reports whether key is present.
+func (f *Frozen[K, V]) Has(key K) bool {
+	_, ok := f.index[key]
+	return ok
+}
+
+// Len returns the number of items.
+func (f *Frozen[K, V]) Len() int {
+	return len(f.keys)
+}
+
+// Range calls fn with each key and value until fn returns false.
+func (f *Frozen[K, V]) Range(fn func(key K, value V) bool) {
+	for i, k := range f.keys {
+		if !fn(k, f.values[i]) {
+			return
+		}
+	}
+}
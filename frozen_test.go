@@ -0,0 +1,65 @@
+package cubby
+
+import "testing"
+
+func TestFreeze(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.Set("y", 2)
+	cache.SetItem("z", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+	frozen := cache.Freeze()
+	cache.Set("x", 10) // later changes do not affect the copy
+	cache.Set("w", 4)
+	cases := map[string]struct {
+		want int
+		ok   bool
+	}{
+		"x": {want: 1, ok: true},
+		"y": {want: 2, ok: true},
+		"z": {},
+		"w": {},
+	}
+	for key, c := range cases {
+		t.Run(key, func(t *testing.T) {
+			got, ok := frozen.Get(key)
+			if got != c.want || ok != c.ok {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if frozen.Has(key) != c.ok {
+				t.Fatalf(errorString, frozen.Has(key), c.ok)
+			}
+		})
+	}
+	if frozen.Len() != 2 {
+		t.Fatalf(errorString, frozen.Len(), 2)
+	}
+	sum := 0
+	frozen.Range(func(_ string, v int) bool {
+		sum += v
+		return true
+	})
+	if sum != 3 {
+		t.Fatalf(errorString, sum, 3)
+	}
+	calls := 0
+	frozen.Range(func(string, int) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Fatalf(errorString, calls, 1)
+	}
+}
+
+func BenchmarkFrozenGet(b *testing.B) {
+	cache := NewCache[string, int]()
+	keys := benchmarkKeys(1024)
+	for i, k := range keys {
+		cache.Set(k, i)
+	}
+	frozen := cache.Freeze()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frozen.Get(keys[i%len(keys)])
+	}
+}
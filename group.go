@@ -0,0 +1,64 @@
+package cubby
+
+import "sync"
+
+// Group deduplicates concurrent loads of the same key, like singleflight but
+// generic. Caches created WithGroup share a Group, so a GetOrCompute load of
+// a key in flight for one cache is awaited by the others rather than
+// repeated. A Group can also be used on its own with Do. The zero value is
+// ready to use, and a Group must not be copied after first use.
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// call is an in-flight load of a Group.
+type call[V any] struct {
+	done  chan struct{}
+	item  Item[V]
+	err   error
+	owner any // the cache that made the call, if any
+}
+
+// Do calls fn and returns its results, unless a call of fn for key is
+// already in flight, in which case it waits for that call and returns its
+// results with shared true. A panic in fn is propagated to its caller, and
+// the calls waiting on it return an error.
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	cl, leader := g.join(key, nil)
+	if !leader {
+		<-cl.done
+		return cl.item.Value, cl.err, true
+	}
+	defer g.finish(key, cl)
+	cl.err = errLoaderPanicked // replaced if fn returns
+	cl.item.Value, cl.err = fn()
+	return cl.item.Value, cl.err, false
+}
+
+// join returns the call in flight for key and false, or, if there is none,
+// registers a new call made by owner and returns it and true. The caller of
+// a new call must finish it.
+func (g *Group[K, V]) join(key K, owner any) (*call[V], bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if cl, ok := g.calls[key]; ok {
+		return cl, false
+	}
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	cl := &call[V]{done: make(chan struct{}), owner: owner}
+	g.calls[key] = cl
+	return cl, true
+}
+
+// finish unregisters cl, the call for key, and releases its waiters.
+func (g *Group[K, V]) finish(key K, cl *call[V]) {
+	g.mu.Lock()
+	if g.calls[key] == cl {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	close(cl.done)
+}
@@ -0,0 +1,128 @@
+package cubby
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestGroupDo(t *testing.T) {
+	var g Group[string, int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	shared := make([]bool, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, shared[i] = g.Do("x", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 7, nil
+			})
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+	leaders := 0
+	for i, v := range results {
+		if v != 7 {
+			t.Fatalf(errorString, v, 7)
+		}
+		if !shared[i] {
+			leaders++
+		}
+	}
+	if leaders != 1 {
+		t.Fatalf(errorString, leaders, 1)
+	}
+	errDown := errors.New("down")
+	if _, err, _ := g.Do("x", func() (int, error) { return 0, errDown }); !errors.Is(err, errDown) {
+		t.Fatalf(errorString, err, errDown)
+	}
+}
+
+func TestWithGroup(t *testing.T) {
+	g := &Group[string, int]{}
+	a := NewCache(WithGroup(g))
+	b := NewCache(WithGroup(g))
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = a.GetOrCompute("x", func() (int, error) {
+			calls.Add(1)
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	v, err := b.GetOrCompute("x", func() (int, error) {
+		calls.Add(1)
+		return 2, nil
+	})
+	<-done
+	if err != nil || v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+	for name, c := range map[string]*Cache[string, int]{"a": a, "b": b} {
+		if got, ok := c.Get("x"); !ok || got != 1 {
+			t.Fatalf("%s:"+errorString, name, got, 1)
+		}
+	}
+}
+
+func TestWithGroupAdoptsDo(t *testing.T) {
+	g := &Group[string, int]{}
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithGroup(g),
+		WithTTL[string, int](1*time.Hour),
+		WithClock[string, int](clock),
+	)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = g.Do("x", func() (int, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+	}()
+	<-started
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	v, err := cache.GetOrCompute("x", func() (int, error) { return 2, nil })
+	<-done
+	if err != nil || v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+	item, _ := cache.GetItem("x")
+	if want := now.Add(1 * time.Hour); !item.ExpiredAt.Equal(want) {
+		t.Fatalf(errorString, item.ExpiredAt, want)
+	}
+}
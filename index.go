@@ -0,0 +1,103 @@
+package cubby
+
+// IndexBy creates a Cache with the given values mapped to the keys derived
+// from them by keyFn. Later values replace earlier ones with the same key.
+func IndexBy[K comparable, V any](values []V, keyFn func(V) K, opts ...Option[K, V]) *Cache[K, V] {
+	c := NewCache(opts...)
+	for _, v := range values {
+		c.Set(keyFn(v), v)
+	}
+	return c
+}
+
+// secondaryIndex maps the index keys given by keyFn to the keys of the items
+// whose values they were derived from.
+type secondaryIndex[K comparable, V any] struct {
+	keyFn func(V) string
+	keys  map[string]map[K]struct{}
+}
+
+// AddIndex adds a secondary index named name that maps the string keyFn
+// derives from each item value to the keys of the items with that value, so
+// items can be found by an attribute of their values with LookupByIndex. The
+// cache keeps the index in sync as items are set, updated, and removed. An
+// index added with the name of an existing one replaces it. keyFn is called
+// with the write lock held and must not call methods of the cache.
+func (c *Cache[K, V]) AddIndex(name string, keyFn func(V) string) {
+	idx := &secondaryIndex[K, V]{keyFn: keyFn, keys: make(map[string]map[K]struct{})}
+	c.lock()
+	defer c.mu.Unlock()
+	if c.indexes == nil {
+		c.indexes = make(map[string]*secondaryIndex[K, V])
+	}
+	c.indexes[name] = idx
+	for _, e := range c.items {
+		idx.add(e)
+	}
+}
+
+// LookupByIndex returns the keys of the unexpired items whose values the
+// secondary index named name maps from value, in no particular order. It
+// returns nil if there are none or no index is named name.
+func (c *Cache[K, V]) LookupByIndex(name, value string) []K {
+	c.rlock()
+	defer c.mu.RUnlock()
+	idx, ok := c.indexes[name]
+	if !ok {
+		return nil
+	}
+	now := c.clock.Now()
+	var keys []K
+	for k := range idx.keys[value] {
+		if !c.items[k].item.expiredAt(now) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// add indexes the item of e.
+func (idx *secondaryIndex[K, V]) add(e *entry[K, V]) {
+	v := idx.keyFn(e.item.Value)
+	if idx.keys[v] == nil {
+		idx.keys[v] = make(map[K]struct{})
+	}
+	idx.keys[v][e.key] = struct{}{}
+}
+
+// remove undoes add for the item of e.
+func (idx *secondaryIndex[K, V]) remove(e *entry[K, V]) {
+	v := idx.keyFn(e.item.Value)
+	delete(idx.keys[v], e.key)
+	if len(idx.keys[v]) == 0 {
+		delete(idx.keys, v)
+	}
+}
+
+// index adds the item of e to every secondary index. The caller must hold the
+// write lock.
+func (c *Cache[K, V]) index(e *entry[K, V]) {
+	for _, idx := range c.indexes {
+		idx.add(e)
+	}
+}
+
+// unindex removes the item of e from every secondary index. The caller must
+// hold the write lock.
+func (c *Cache[K, V]) unindex(e *entry[K, V]) {
+	for _, idx := range c.indexes {
+		idx.remove(e)
+	}
+}
+
+// setValue replaces the value of the item of e in place, keeping the
+// secondary indexes in sync, unless the cache is closed. The caller must hold
+// the write lock.
+func (c *Cache[K, V]) setValue(e *entry[K, V], v V) {
+	if c.closed {
+		return
+	}
+	c.unindex(e)
+	e.item.Value = v
+	c.index(e)
+}
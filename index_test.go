@@ -0,0 +1,62 @@
+package cubby
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIndexBy(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	users := []user{{1, "ann"}, {2, "bob"}, {1, "amy"}}
+	cache := IndexBy(users, func(u user) int { return u.ID })
+	if cache.Len() != 2 {
+		t.Fatalf(errorString, cache.Len(), 2)
+	}
+	want := map[int]string{1: "amy", 2: "bob"}
+	for id, name := range want {
+		if u, ok := cache.Get(id); !ok || u.Name != name {
+			t.Fatalf(errorString, u.Name, name)
+		}
+	}
+}
+
+func TestLookupByIndex(t *testing.T) {
+	type user struct{ name, team string }
+	cache := NewCache[int, user]()
+	cache.Set(1, user{"ann", "red"})
+	cache.Set(2, user{"bob", "blue"})
+	cache.AddIndex("team", func(u user) string { return u.team })
+	cache.Set(3, user{"cat", "red"})
+	cache.SetItem(4, Item[user]{Value: user{"dan", "red"}, CreatedAt: past, ExpiredAt: past})
+	steps := []struct {
+		name string
+		do   func()
+		team string
+		want []int
+	}{
+		{name: "added before and after", do: func() {}, team: "red", want: []int{1, 3}},
+		{name: "updated", do: func() { cache.Set(1, user{"ann", "blue"}) }, team: "blue", want: []int{1, 2}},
+		{name: "updated away", do: func() {}, team: "red", want: []int{3}},
+		{name: "deleted", do: func() { cache.Delete(2) }, team: "blue", want: []int{1}},
+		{name: "updated in place", do: func() {
+			cache.UpdateAll(func(k int, u user) (user, bool) { return user{u.name, "green"}, k == 3 })
+		}, team: "green", want: []int{3}},
+		{name: "expired swept", do: func() { cache.ClearExpired() }, team: "red", want: nil},
+		{name: "unknown value", do: func() {}, team: "pink", want: nil},
+		{name: "cleared", do: cache.Clear, team: "blue", want: nil},
+	}
+	for _, s := range steps {
+		s.do()
+		got := cache.LookupByIndex("team", s.team)
+		slices.Sort(got)
+		if !slices.Equal(got, s.want) {
+			t.Fatalf("%s:"+errorString, s.name, got, s.want)
+		}
+	}
+	if got := cache.LookupByIndex("missing", "red"); got != nil {
+		t.Fatalf(errorString, got, nil)
+	}
+}
@@ -0,0 +1,39 @@
+package cubby
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// itemJSON is the wire format of an Item.
+type itemJSON[V any] struct {
+	Value     V          `json:"value"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiredAt *time.Time `json:"expired_at,omitempty"`
+	Pinned    bool       `json:"pinned,omitempty"`
+}
+
+// MarshalJSON encodes the item as an object with value, created_at,
+// expired_at, and pinned fields. expired_at is omitted if the item never
+// expires and pinned is omitted if the item is not pinned.
+func (i Item[V]) MarshalJSON() ([]byte, error) {
+	v := itemJSON[V]{Value: i.Value, CreatedAt: i.CreatedAt, Pinned: i.Pinned}
+	if !i.ExpiredAt.IsZero() {
+		v.ExpiredAt = &i.ExpiredAt
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes an item encoded by MarshalJSON. A missing expired_at
+// field yields an item that never expires.
+func (i *Item[V]) UnmarshalJSON(data []byte) error {
+	var v itemJSON[V]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*i = Item[V]{Value: v.Value, CreatedAt: v.CreatedAt, Pinned: v.Pinned}
+	if v.ExpiredAt != nil {
+		i.ExpiredAt = *v.ExpiredAt
+	}
+	return nil
+}
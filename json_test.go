@@ -0,0 +1,62 @@
+package cubby
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemJSON(t *testing.T) {
+	created := time.Date(2023, 12, 1, 8, 30, 0, 0, time.UTC)
+	cases := map[string]struct {
+		item Item[string]
+		want string
+	}{
+		"no expiration": {
+			item: Item[string]{Value: "foo", CreatedAt: created},
+			want: `{"value":"foo","created_at":"2023-12-01T08:30:00Z"}`,
+		},
+		"expiration": {
+			item: Item[string]{
+				Value:     "bar",
+				CreatedAt: created,
+				ExpiredAt: created.Add(1 * time.Hour),
+			},
+			want: `{"value":"bar","created_at":"2023-12-01T08:30:00Z","expired_at":"2023-12-01T09:30:00Z"}`,
+		},
+		"pinned": {
+			item: Item[string]{Value: "baz", CreatedAt: created, Pinned: true},
+			want: `{"value":"baz","created_at":"2023-12-01T08:30:00Z","pinned":true}`,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(c.item)
+			if err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			if got := string(data); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			var item Item[string]
+			if err := json.Unmarshal(data, &item); err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			if item.Value != c.item.Value ||
+				!item.CreatedAt.Equal(c.item.CreatedAt) ||
+				!item.ExpiredAt.Equal(c.item.ExpiredAt) ||
+				item.Pinned != c.item.Pinned {
+				t.Fatalf(errorString, item, c.item)
+			}
+		})
+	}
+}
+
+func TestItemUnmarshalJSONError(t *testing.T) {
+	var item Item[int]
+	err := json.Unmarshal([]byte(`{"value":"not a number"}`), &item)
+	if err == nil || !strings.Contains(err.Error(), "value") {
+		t.Fatalf(errorString, err, "an error decoding value")
+	}
+}
@@ -0,0 +1,43 @@
+package cubby
+
+// keyLockStripes is the number of locks that keys are striped across by
+// LockKey.
+const keyLockStripes = 64
+
+// LockKey locks key, blocking until it is unlocked by any other holder, and
+// returns the function that unlocks it. Keys are striped across a fixed set
+// of locks, so unrelated keys occasionally share one. LockKey coordinates
+// only callers of LockKey and Update; other methods of the cache do not wait
+// for it. Locking a key twice from the same goroutine, or locking two keys at
+// once, may deadlock.
+func (c *Cache[K, V]) LockKey(key K) (unlock func()) {
+	mu := &c.keyLocks[hashKey(key)%keyLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Update replaces the item value mapped to key with fn of it and returns the
+// result. fn is passed the current value and true, or the zero value and
+// false if key is missing or expired, in which case the result is stored in
+// a new item as by Set.
+//
+// Update holds the key's lock from LockKey while fn runs, but not the cache's
+// lock, so updates to the same key are serialized while updates to different
+// keys, and all other access to the cache, proceed in parallel.
+func (c *Cache[K, V]) Update(key K, fn func(value V, ok bool) V) V {
+	unlock := c.LockKey(key)
+	defer unlock()
+	old, ok := c.getLive(key)
+	v := fn(old, ok)
+	c.lock()
+	if e, found := c.items[key]; ok && found {
+		c.setValue(e, v)
+		c.touch(e)
+		c.mu.Unlock()
+		return v
+	}
+	evicted, _ := c.set(key, c.newItem(v), 1)
+	c.mu.Unlock()
+	c.notify(evicted)
+	return v
+}
@@ -0,0 +1,125 @@
+package cubby
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUpdate(t *testing.T) {
+	cases := map[string]struct {
+		item   *Item[int]
+		want   int
+		wantOk bool
+	}{
+		"present": {item: &Item[int]{Value: 1, CreatedAt: now}, want: 2, wantOk: true},
+		"expired": {item: &Item[int]{Value: 1, CreatedAt: past, ExpiredAt: past}, want: 1},
+		"missing": {want: 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			if c.item != nil {
+				cache.SetItem("x", *c.item)
+			}
+			var gotOk bool
+			got := cache.Update("x", func(v int, ok bool) int {
+				gotOk = ok
+				return v + 1
+			})
+			if got != c.want || gotOk != c.wantOk {
+				t.Fatalf(errorString, []any{got, gotOk}, []any{c.want, c.wantOk})
+			}
+			item, _ := cache.GetItem("x")
+			if item.Value != c.want || item.IsExpired() {
+				t.Fatalf(errorString, item, c.want)
+			}
+			if c.wantOk && item.CreatedAt != c.item.CreatedAt {
+				t.Fatalf(errorString, item.CreatedAt, c.item.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	cache := NewCache[string, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache.Update(strconv.Itoa(i%4), func(v int, _ bool) int { return v + 1 })
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 4; i++ {
+		if got, _ := cache.Get(strconv.Itoa(i)); got != 25 {
+			t.Fatalf(errorString, got, 25)
+		}
+	}
+}
+
+func TestLockKey(t *testing.T) {
+	cache := NewCache[string, int]()
+	unlock := cache.LockKey("x")
+	done := make(chan struct{})
+	go func() {
+		cache.Update("x", func(v int, _ bool) int { return v + 1 })
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("Wanted Update to wait for LockKey but it did not")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if _, ok := cache.Get("y"); ok { // other access is not blocked
+		t.Fatalf(errorString, ok, false)
+	}
+	unlock()
+	<-done
+	if got, _ := cache.Get("x"); got != 1 {
+		t.Fatalf(errorString, got, 1)
+	}
+}
+
+// work simulates an in-place update of a large value.
+func work(v int) int {
+	for i := 0; i < 1000; i++ {
+		v = v*31 + i
+	}
+	return v
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	cache := NewCache[string, int]()
+	keys := benchmarkKeys(1024)
+	b.ResetTimer()
+	var next atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(128)) // spread goroutines over keys
+		for pb.Next() {
+			cache.Update(keys[i%len(keys)], func(v int, _ bool) int { return work(v) })
+			i++
+		}
+	})
+}
+
+func BenchmarkUpdateUnderCacheLock(b *testing.B) {
+	cache := NewCache[string, int]()
+	keys := benchmarkKeys(1024)
+	b.ResetTimer()
+	var next atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(128)) // spread goroutines over keys
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			cache.Transact(func(tx *Tx[string, int]) {
+				v, _ := tx.Get(key)
+				tx.Set(key, work(v))
+			})
+			i++
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package cubby
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logEvictions logs each eviction at debug level if the cache has a logger.
+func (c *Cache[K, V]) logEvictions(ctx context.Context, evicted []eviction[K, V]) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	for _, ev := range evicted {
+		c.logger.DebugContext(ctx, "cubby: evicted item",
+			"cache", c.name, "key", ev.key, "reason", ev.reason.String())
+	}
+}
+
+// logSweep logs a sweep that removed n expired items at info level if the
+// cache has a logger.
+func (c *Cache[K, V]) logSweep(n int) {
+	if c.logger == nil || n == 0 {
+		return
+	}
+	c.logger.Info("cubby: swept expired items", "cache", c.name, "count", n)
+}
+
+// logLoadError logs the error of a failed load of key at error level if the
+// cache has a logger.
+func (c *Cache[K, V]) logLoadError(key K, err error) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.Error("cubby: load failed", "cache", c.name, "key", key, "err", err)
+}
@@ -0,0 +1,66 @@
+package cubby
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cache := NewCache(
+		WithName[string, int]("test"),
+		WithLogger[string, int](logger),
+	)
+	cases := []struct {
+		name string
+		do   func()
+		want []string
+	}{
+		{
+			name: "delete",
+			do: func() {
+				cache.Set("x", 1)
+				cache.Delete("x")
+			},
+			want: []string{"level=DEBUG", "cache=test", "key=x", "reason=deleted"},
+		},
+		{
+			name: "sweep",
+			do: func() {
+				cache.SetItem("y", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+				cache.ClearExpired()
+			},
+			want: []string{"level=INFO", `msg="cubby: swept expired items"`, "count=1"},
+		},
+		{
+			name: "load error",
+			do: func() {
+				_, _ = cache.GetOrCompute("z", func() (int, error) {
+					return 0, errors.New("backend down")
+				})
+			},
+			want: []string{"level=ERROR", "key=z", `err="backend down"`},
+		},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		c.do()
+		got := buf.String()
+		for _, w := range c.want {
+			if !strings.Contains(got, w) {
+				t.Fatalf("%s:"+errorString, c.name, got, w)
+			}
+		}
+	}
+}
+
+func TestWithoutLogger(t *testing.T) {
+	cache := NewCache[string, int]()
+	if cache.observed() {
+		t.Fatalf("Got an observed cache without callbacks, subscribers, or a logger")
+	}
+}
@@ -0,0 +1,88 @@
+package cubby
+
+// lruList is an intrusive doubly linked list of entries ordered from most to
+// least recently used. Entries link to their neighbors directly, so pushing,
+// moving, and removing an entry take constant time with no allocation. The
+// list is circular through a sentinel root whose next entry is the most
+// recently used and whose prev entry is the least.
+type lruList[K comparable, V any] struct {
+	root entry[K, V]
+}
+
+// newLRUList returns an empty lruList.
+func newLRUList[K comparable, V any]() *lruList[K, V] {
+	l := &lruList[K, V]{}
+	l.init()
+	return l
+}
+
+// init empties l.
+func (l *lruList[K, V]) init() {
+	l.root.next = &l.root
+	l.root.prev = &l.root
+}
+
+// pushFront inserts e as the most recently used entry.
+func (l *lruList[K, V]) pushFront(e *entry[K, V]) {
+	e.prev = &l.root
+	e.next = l.root.next
+	e.prev.next = e
+	e.next.prev = e
+}
+
+// remove unlinks e from l. Removing an entry not in l does nothing.
+func (l *lruList[K, V]) remove(e *entry[K, V]) {
+	if e.next == nil {
+		return
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next = nil, nil
+}
+
+// moveToFront makes e the most recently used entry.
+func (l *lruList[K, V]) moveToFront(e *entry[K, V]) {
+	if l.root.next == e || e.next == nil {
+		return
+	}
+	l.remove(e)
+	l.pushFront(e)
+}
+
+// back returns the least recently used entry, or nil if l is empty.
+func (l *lruList[K, V]) back() *entry[K, V] {
+	if l.root.prev == &l.root {
+		return nil
+	}
+	return l.root.prev
+}
+
+// prev returns the entry used just more recently than e, or nil if e is the
+// most recently used.
+func (l *lruList[K, V]) prev(e *entry[K, V]) *entry[K, V] {
+	if e.prev == &l.root {
+		return nil
+	}
+	return e.prev
+}
+
+// EvictionCandidates returns up to n keys of a bounded cache in the order
+// they would be evicted to make room: least recently used first, skipping
+// pinned items. Nothing is evicted, and the OnBeforeEvict callback, which may
+// veto a candidate, is not consulted. It returns nil for an unbounded cache.
+func (c *Cache[K, V]) EvictionCandidates(n int) []K {
+	if c.lru == nil || n <= 0 {
+		return nil
+	}
+	c.rlock()
+	defer c.mu.RUnlock()
+	c.lruMu.Lock()
+	defer c.lruMu.Unlock()
+	keys := make([]K, 0, min(n, len(c.items)))
+	for e := c.lru.back(); e != nil && len(keys) < n; e = c.lru.prev(e) {
+		if !e.item.Pinned {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
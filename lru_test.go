@@ -0,0 +1,94 @@
+package cubby
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestLRUList(t *testing.T) {
+	l := newLRUList[string, int]()
+	if l.back() != nil {
+		t.Fatalf("Got a back entry of an empty list but wanted nil")
+	}
+	a, b, c := &entry[string, int]{key: "a"}, &entry[string, int]{key: "b"}, &entry[string, int]{key: "c"}
+	l.pushFront(a)
+	l.pushFront(b)
+	l.pushFront(c)
+	cases := []struct {
+		name string
+		do   func()
+		want []string // least to most recently used
+	}{
+		{name: "push", do: func() {}, want: []string{"a", "b", "c"}},
+		{name: "move back to front", do: func() { l.moveToFront(a) }, want: []string{"b", "c", "a"}},
+		{name: "move front to front", do: func() { l.moveToFront(a) }, want: []string{"b", "c", "a"}},
+		{name: "remove middle", do: func() { l.remove(c) }, want: []string{"b", "a"}},
+		{name: "remove twice", do: func() { l.remove(c) }, want: []string{"b", "a"}},
+		{name: "move removed", do: func() { l.moveToFront(c) }, want: []string{"b", "a"}},
+		{name: "init", do: l.init, want: nil},
+	}
+	for _, c := range cases {
+		c.do()
+		var got []string
+		for e := l.back(); e != nil; e = l.prev(e) {
+			got = append(got, e.key)
+		}
+		if !slices.Equal(got, c.want) {
+			t.Fatalf("%s:"+errorString, c.name, got, c.want)
+		}
+	}
+}
+
+func TestEvictionCandidates(t *testing.T) {
+	cache := NewCache(WithCapacity[string, int](10))
+	for i, k := range []string{"a", "b", "c", "d"} {
+		cache.Set(k, i)
+	}
+	cache.Get("a")
+	cache.Pin("c")
+	cases := []struct {
+		n    int
+		want []string
+	}{
+		{n: 0, want: nil},
+		{n: 2, want: []string{"b", "d"}},
+		{n: 10, want: []string{"b", "d", "a"}},
+	}
+	for _, c := range cases {
+		if got := cache.EvictionCandidates(c.n); !slices.Equal(got, c.want) {
+			t.Fatalf("%d:"+errorString, c.n, got, c.want)
+		}
+	}
+	if got := NewCache[string, int]().EvictionCandidates(1); got != nil {
+		t.Fatalf(errorString, got, nil)
+	}
+}
+
+// BenchmarkLRU measures Set with an eviction and Get of a bounded cache at
+// growing sizes. Constant time per operation shows as a flat ns/op.
+func BenchmarkLRU(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		keys := benchmarkKeys(2 * size)
+		b.Run("Set/"+strconv.Itoa(size), func(b *testing.B) {
+			cache := NewCache(WithCapacity[string, int](size))
+			for i := 0; i < size; i++ {
+				cache.Set(keys[i], i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Set(keys[i%len(keys)], i) // evicts once full
+			}
+		})
+		b.Run("Get/"+strconv.Itoa(size), func(b *testing.B) {
+			cache := NewCache(WithCapacity[string, int](size))
+			for i := 0; i < size; i++ {
+				cache.Set(keys[i], i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Get(keys[i%size])
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cubby
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Sizer is implemented by values that can report roughly how many bytes they
+// hold, including memory they refer to, for MemoryEstimate.
+type Sizer interface {
+	Size() int
+}
+
+// slotOverhead approximates the bytes a map slot, its share of the map's
+// bookkeeping, and a slot in the cache's order take per item, beyond the key
+// and entry themselves.
+const slotOverhead = 24
+
+// MemoryEstimate returns a rough estimate of the bytes the items in the cache
+// take, for capacity planning. Each item is counted as a fixed overhead for
+// its key, bookkeeping, and timestamps, plus the size of its value: the
+// weight given by the cache's weigher if it was created WithWeigher, or else
+// Size if the value is a Sizer. Memory that other values refer to, such as
+// the bytes of a string, is not counted. The estimate is meant to be of the
+// right order of magnitude, not exact.
+func (c *Cache[K, V]) MemoryEstimate() int64 {
+	overhead := int64(reflect.TypeOf((*K)(nil)).Elem().Size()) +
+		int64(reflect.TypeOf((*entry[K, V])(nil)).Elem().Size()) + slotOverhead
+	c.rlock()
+	defer c.mu.RUnlock()
+	total := overhead * int64(len(c.items))
+	for k, e := range c.items {
+		if c.weigher != nil {
+			total += c.weigher(k, e.item.Value)
+		} else if s, ok := any(e.item.Value).(Sizer); ok {
+			total += int64(s.Size())
+		}
+	}
+	return total
+}
+
+// heapInUse reports the bytes of allocated heap objects.
+func heapInUse() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// WatchMemory adds a job, as AddJob does, that samples the process's heap at
+// every tick denoted by d. Once the heap reaches high bytes, the job sheds
+// load at each tick until the heap falls to low bytes: it evicts the least
+// recently used unpinned items in proportion to the excess and runs the
+// garbage collector so the next sample reflects what was freed. Evicted items
+// are reported as EvictCapacity. Unlike WithMaxCost, this adapts to the real
+// memory use of the process rather than an estimate per item.
+//
+// It panics if d is not positive or low is greater than high.
+func (tc *TickingCache[K, V]) WatchMemory(d time.Duration, high, low uint64) (cancel func()) {
+	checkInterval("WatchMemory", d)
+	if low > high {
+		panic(fmt.Sprintf("cubby: low watermark %d above high watermark %d", low, high))
+	}
+	return tc.AddJob(d, tc.memoryJob(high, low, heapInUse))
+}
+
+// memoryJob returns the job run by WatchMemory, which samples the heap with
+// heapInUse. Tests pass their own sampler to simulate memory pressure.
+func (c *Cache[K, V]) memoryJob(high, low uint64, heapInUse func() uint64) func() {
+	shedding := false
+	return func() {
+		heap := heapInUse()
+		switch {
+		case heap >= high:
+			shedding = true
+		case heap <= low:
+			shedding = false
+		}
+		if !shedding {
+			return
+		}
+		c.lock()
+		n := int(float64(len(c.items)) * float64(heap-low) / float64(heap))
+		evicted := c.shed(max(n, 1))
+		c.mu.Unlock()
+		c.notify(evicted)
+		runtime.GC()
+	}
+}
+
+// shed evicts up to n of the least recently used unpinned items and returns
+// them. Without an LRU list, items are ordered by when they were last
+// accessed. The caller must hold the write lock.
+func (c *Cache[K, V]) shed(n int) []eviction[K, V] {
+	var keys []K
+	if c.lru != nil {
+		for e := c.lru.back(); e != nil && len(keys) < n; e = c.lru.prev(e) {
+			if !e.item.Pinned {
+				keys = append(keys, e.key)
+			}
+		}
+	} else {
+		used := make(map[K]int64)
+		for k, e := range c.items {
+			if e.item.Pinned {
+				continue
+			}
+			keys = append(keys, k)
+			used[k] = e.accessed.Load()
+			if used[k] == 0 {
+				used[k] = e.item.CreatedAt.UnixNano()
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return used[keys[i]] < used[keys[j]]
+		})
+		keys = keys[:min(n, len(keys))]
+	}
+	evicted := make([]eviction[K, V], 0, len(keys))
+	for _, k := range keys {
+		e, _ := c.unlink(k)
+		evicted = append(evicted, eviction[K, V]{k, e.item, EvictCapacity})
+	}
+	return evicted
+}
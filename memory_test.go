@@ -0,0 +1,163 @@
+package cubby
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+// sized is a Sizer value whose size is itself.
+type sized int
+
+func (s sized) Size() int { return int(s) }
+
+func TestMemoryEstimate(t *testing.T) {
+	perItem := int64(reflect.TypeOf("").Size()) +
+		int64(reflect.TypeOf((*entry[string, sized])(nil)).Elem().Size()) + slotOverhead
+	byKeyLength := func(key string, _ sized) int64 { return int64(len(key)) * 10 }
+	cases := map[string]struct {
+		opts  []Option[string, sized]
+		items map[string]sized
+		want  int64
+	}{
+		"empty":   {want: 0},
+		"sizer":   {items: map[string]sized{"a": 100, "bb": 50}, want: 2*perItem + 150},
+		"weigher": {opts: []Option[string, sized]{WithWeigher(byKeyLength)}, items: map[string]sized{"a": 100, "bb": 50}, want: 2*perItem + 30},
+		"bounded": {opts: []Option[string, sized]{WithCapacity[string, sized](10)}, items: map[string]sized{"a": 1}, want: perItem + 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(c.opts...)
+			cache.SetMany(c.items)
+			if got := cache.MemoryEstimate(); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMemoryEstimateFallback(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("a", 1)
+	perItem := cache.MemoryEstimate()
+	want := int64(reflect.TypeOf("").Size()) +
+		int64(reflect.TypeOf((*entry[string, int])(nil)).Elem().Size()) + slotOverhead
+	if perItem != want {
+		t.Fatalf("per-item overhead:"+errorString, perItem, want)
+	}
+	steps := []struct {
+		name string
+		do   func()
+		want int64
+	}{
+		{name: "set", do: func() { cache.Set("b", 2) }, want: 2 * perItem},
+		{name: "update", do: func() { cache.Set("b", 3) }, want: 2 * perItem},
+		{name: "delete", do: func() { cache.Delete("a") }, want: perItem},
+		{name: "clear", do: cache.Clear, want: 0},
+	}
+	for _, s := range steps {
+		s.do()
+		if got := cache.MemoryEstimate(); got != s.want {
+			t.Fatalf("%s:"+errorString, s.name, got, s.want)
+		}
+	}
+}
+
+// heapSamples returns a heap sampler for memoryJob that reports each of
+// samples in turn, repeating the last one.
+func heapSamples(samples ...uint64) func() uint64 {
+	return func() uint64 {
+		heap := samples[0]
+		if len(samples) > 1 {
+			samples = samples[1:]
+		}
+		return heap
+	}
+}
+
+func TestMemoryJob(t *testing.T) {
+	cases := map[string]struct {
+		samples []uint64
+		bounded bool
+		want    []int // lengths after each tick
+	}{
+		"below high":             {samples: []uint64{50, 80}, want: []int{10, 10}},
+		"above high":             {samples: []uint64{100}, want: []int{5}},
+		"sheds until low":        {samples: []uint64{100, 90, 50}, want: []int{5, 3, 3}},
+		"stops at low":           {samples: []uint64{100, 50, 60}, want: []int{5, 5, 5}},
+		"sheds at least one":     {samples: []uint64{100, 51}, want: []int{5, 4}},
+		"bounded cache":          {samples: []uint64{100}, bounded: true, want: []int{5}},
+		"empties without relief": {samples: []uint64{1000}, want: []int{1, 0, 0}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option[string, int]{}
+			if c.bounded {
+				opts = append(opts, WithCapacity[string, int](100))
+			}
+			cache := NewCache(opts...)
+			for i := 0; i < 10; i++ {
+				cache.Set(strconv.Itoa(i), i)
+			}
+			job := cache.memoryJob(100, 50, heapSamples(c.samples...))
+			var got []int
+			for range c.want {
+				job()
+				got = append(got, cache.Len())
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMemoryJobEvictsLeastRecentlyUsed(t *testing.T) {
+	for _, bounded := range []bool{false, true} {
+		clock := cubbytest.NewFakeClock(now)
+		opts := []Option[string, int]{WithClock[string, int](clock)}
+		if bounded {
+			opts = append(opts, WithCapacity[string, int](100))
+		}
+		cache := NewCache(opts...)
+		for i := 0; i < 4; i++ {
+			clock.Advance(1 * time.Second)
+			cache.Set(strconv.Itoa(i), i)
+		}
+		clock.Advance(1 * time.Second)
+		cache.Get("0")
+		cache.Pin("1")
+		cache.memoryJob(100, 50, heapSamples(100))()
+		got := sortedKeys(cache.Items())
+		if want := []string{"0", "1"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf(errorString, got, want)
+		}
+	}
+}
+
+func TestWatchMemory(t *testing.T) {
+	// A zero high watermark keeps the real heap above it, so the job sheds
+	// at every tick.
+	cache := NewTickingCache[string, int](1 * time.Hour)
+	defer cache.Stop()
+	cache.Set("x", 1)
+	cache.WatchMemory(1*time.Millisecond, 0, 0)
+	time.Sleep(20 * time.Millisecond)
+	if cache.Len() != 0 {
+		t.Fatalf(errorString, cache.Len(), 0)
+	}
+}
+
+func TestWatchMemoryPanics(t *testing.T) {
+	cache := NewTickingCache[string, int](1 * time.Hour)
+	defer cache.Stop()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Wanted a panic but got none")
+		}
+	}()
+	cache.WatchMemory(1*time.Second, 50, 100)
+}
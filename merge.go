@@ -0,0 +1,56 @@
+package cubby
+
+// MergeExpired chooses how Merge treats expired items.
+type MergeExpired int
+
+const (
+	// MergeSkipExpiredSource skips items of the source cache that are
+	// expired, so they do not overwrite or join the destination. It is the
+	// default.
+	MergeSkipExpiredSource MergeExpired = iota
+	// MergeSkipExpiredDestination leaves expired items of the destination
+	// cache as they are, for its own sweeps to remove, rather than replace
+	// them with the source's items.
+	MergeSkipExpiredDestination
+	// MergeAll merges every item regardless of whether it is expired.
+	MergeAll
+)
+
+// Merge adds the items of src to the cache, replacing the items of keys present
+// in both, and returns how many it added or replaced; items the cache has no
+// room for are not counted. Items keep their values and dates. expired chooses
+// how expired items in either cache are treated; both are judged by the
+// destination cache's clock. src is read from a snapshot taken under its own
+// lock, so the caches are never locked together. If the cache is closed,
+// nothing is merged and it returns 0.
+func (c *Cache[K, V]) Merge(src *Cache[K, V], expired MergeExpired) int {
+	items := src.Items()
+	var evicted []eviction[K, V]
+	n := 0
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0
+	}
+	now := c.clock.Now()
+	for k, item := range items {
+		switch expired {
+		case MergeSkipExpiredSource:
+			if item.expiredAt(now) {
+				continue
+			}
+		case MergeSkipExpiredDestination:
+			if e, ok := c.items[k]; ok && e.item.expiredAt(now) {
+				continue
+			}
+		}
+		ev, stored := c.set(k, item, 1)
+		evicted = append(evicted, ev...)
+		if stored {
+			n++
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return n
+}
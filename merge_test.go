@@ -0,0 +1,46 @@
+package cubby
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	cases := map[MergeExpired]struct {
+		n    int
+		want map[string]int
+	}{
+		MergeSkipExpiredSource:      {n: 3, want: map[string]int{"live": 10, "stale": 20, "fresh": 30}},
+		MergeSkipExpiredDestination: {n: 3, want: map[string]int{"live": 10, "stale": 2, "fresh": 30, "gone": 40}},
+		MergeAll:                    {n: 4, want: map[string]int{"live": 10, "stale": 20, "fresh": 30, "gone": 40}},
+	}
+	for policy, c := range cases {
+		dst, src := NewCache[string, int](), NewCache[string, int]()
+		dst.Set("live", 1)
+		dst.SetItem("stale", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+		src.Set("live", 10)
+		src.Set("stale", 20)
+		src.Set("fresh", 30)
+		src.SetItem("gone", Item[int]{Value: 40, CreatedAt: past, ExpiredAt: past})
+		if n := dst.Merge(src, policy); n != c.n {
+			t.Fatalf("%d:"+errorString, policy, n, c.n)
+		}
+		got := make(map[string]int)
+		for k, item := range dst.Items() {
+			got[k] = item.Value
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%d:"+errorString, policy, got, c.want)
+		}
+	}
+}
+
+func TestMergeKeepsDates(t *testing.T) {
+	dst, src := NewCache[string, int](), NewCache[string, int]()
+	want := Item[int]{Value: 1, CreatedAt: past, ExpiredAt: future}
+	src.SetItem("x", want)
+	dst.Merge(src, MergeSkipExpiredSource)
+	if got, _ := dst.GetItem("x"); got != want {
+		t.Fatalf(errorString, got, want)
+	}
+}
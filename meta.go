@@ -0,0 +1,41 @@
+package cubby
+
+import "maps"
+
+// SetWithMeta is like Set but also attaches meta to the item, such as the
+// system it came from or a trace ID, so that bookkeeping need not be added to
+// V. The metadata is kept beside the item rather than in it, so that Item
+// stays comparable, and is retrieved with GetMeta. It is copied, replaced by
+// the next SetWithMeta of key, and removed by any other set of key. It is not
+// included in snapshots.
+func (c *Cache[K, V]) SetWithMeta(key K, value V, meta map[string]any) {
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	evicted, _ := c.set(key, c.newItem(value), 1)
+	if e, ok := c.items[key]; ok {
+		e.meta = maps.Clone(meta)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// GetMeta retrieves the item mapped to key along with a copy of the metadata
+// attached to it by SetWithMeta, which is nil if there is none. Expired items
+// are treated as GetItem treats them.
+func (c *Cache[K, V]) GetMeta(key K) (Item[V], map[string]any, bool) {
+	c.rlock()
+	item, ok := c.read(key)
+	var meta map[string]any
+	if ok {
+		meta = maps.Clone(c.items[key].meta)
+	}
+	c.mu.RUnlock()
+	if ok && c.lazyExpiration && item.expiredAt(c.clock.Now()) {
+		c.expire(key)
+		return Item[V]{}, nil, false
+	}
+	return item, meta, ok
+}
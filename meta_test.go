@@ -0,0 +1,54 @@
+package cubby
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetWithMeta(t *testing.T) {
+	cases := map[string]struct {
+		then func(*Cache[string, int])
+		want map[string]any
+		ok   bool
+	}{
+		"attached": {
+			then: func(*Cache[string, int]) {},
+			want: map[string]any{"source": "db", "checksum": 42},
+			ok:   true,
+		},
+		"replaced": {
+			then: func(c *Cache[string, int]) { c.SetWithMeta("x", 2, map[string]any{"source": "api"}) },
+			want: map[string]any{"source": "api"},
+			ok:   true,
+		},
+		"removed by set": {
+			then: func(c *Cache[string, int]) { c.Set("x", 2) },
+			ok:   true,
+		},
+		"deleted": {
+			then: func(c *Cache[string, int]) { c.Delete("x") },
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			meta := map[string]any{"source": "db", "checksum": 42}
+			cache.SetWithMeta("x", 1, meta)
+			meta["source"] = "changed" // the cache keeps a copy
+			c.then(cache)
+			item, got, ok := cache.GetMeta("x")
+			if ok != c.ok || !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if want, _ := cache.GetItem("x"); item != want {
+				t.Fatalf(errorString, item, want)
+			}
+			if got != nil {
+				got["source"] = "changed" // callers get a copy
+				if _, again, _ := cache.GetMeta("x"); !reflect.DeepEqual(again, c.want) {
+					t.Fatalf(errorString, again, c.want)
+				}
+			}
+		})
+	}
+}
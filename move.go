@@ -0,0 +1,49 @@
+package cubby
+
+import "sync/atomic"
+
+// cacheIDs numbers caches in the order they are created, so that operations
+// locking two caches always lock them in the same order.
+var cacheIDs atomic.Uint64
+
+// Move removes the item mapped to key from one cache and adds it to another,
+// keeping its value, timestamps, and pin, and returns true if it was present.
+// If to is closed or has no room for the item, because it is full and was
+// created WithRejectWhenFull or every item in it is pinned, it returns false
+// and leaves the item in from. Both caches are locked for the move, so no other
+// goroutine sees the item in both caches or in neither. The item's removal from
+// from is not reported as an eviction, but items evicted from to to make room
+// for it are.
+func Move[K comparable, V any](from, to *Cache[K, V], key K) bool {
+	if from == to {
+		from.rlock()
+		defer from.mu.RUnlock()
+		_, ok := from.items[key]
+		return ok
+	}
+	first, second := from, to
+	if second.id < first.id {
+		first, second = second, first
+	}
+	first.lock()
+	second.lock()
+	if to.closed {
+		second.mu.Unlock()
+		first.mu.Unlock()
+		return false
+	}
+	e, ok := from.items[key]
+	var evicted []eviction[K, V]
+	if ok {
+		evicted, ok = to.set(key, e.item, e.cost)
+		if ok {
+			from.remove(key)
+		} else if n := len(evicted); n > 0 {
+			evicted = evicted[:n-1] // the item itself, which stays in from
+		}
+	}
+	second.mu.Unlock()
+	first.mu.Unlock()
+	to.notify(evicted)
+	return ok
+}
@@ -0,0 +1,132 @@
+package cubby
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMove(t *testing.T) {
+	cases := map[string]struct {
+		key      string
+		want     bool
+		fromLen  int
+		toLen    int
+		toHasKey bool
+	}{
+		"present": {key: "a", want: true, fromLen: 1, toLen: 2, toHasKey: true},
+		"missing": {key: "z", want: false, fromLen: 2, toLen: 1, toHasKey: false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			from, to := NewCache[string, int](), NewCache[string, int]()
+			from.SetItem("a", Item[int]{Value: 1, CreatedAt: past, ExpiredAt: future, Pinned: true})
+			from.Set("b", 2)
+			to.Set("c", 3)
+			if got := Move(from, to, c.key); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if from.Len() != c.fromLen || to.Len() != c.toLen {
+				t.Fatalf(errorString, []int{from.Len(), to.Len()}, []int{c.fromLen, c.toLen})
+			}
+			item, ok := to.GetItem(c.key)
+			if ok != c.toHasKey {
+				t.Fatalf(errorString, ok, c.toHasKey)
+			}
+			want := Item[int]{Value: 1, CreatedAt: past, ExpiredAt: future, Pinned: true}
+			if ok && item != want {
+				t.Fatalf(errorString, item, want)
+			}
+		})
+	}
+}
+
+func TestMoveSameCache(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("a", 1)
+	if !Move(cache, cache, "a") {
+		t.Fatalf("Got false moving a present key but wanted true")
+	}
+	if v, _ := cache.Get("a"); v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+}
+
+func TestMoveEvictsFromDestination(t *testing.T) {
+	var got []string
+	from := NewCache(WithOnEvict(func(key string, _ int, reason EvictReason) {
+		t.Fatalf("Got eviction of %s (%v) from source but wanted none", key, reason)
+	}))
+	to := NewCache(
+		WithCapacity[string, int](1),
+		WithOnEvict(func(key string, _ int, reason EvictReason) {
+			got = append(got, key+" "+reason.String())
+		}),
+	)
+	from.Set("a", 1)
+	to.Set("b", 2)
+	Move(from, to, "a")
+	if len(got) != 1 || got[0] != "b capacity" {
+		t.Fatalf(errorString, got, []string{"b capacity"})
+	}
+}
+
+func TestMoveRefused(t *testing.T) {
+	cases := map[string][]Option[string, int]{
+		"reject when full": {WithCapacity[string, int](1), WithRejectWhenFull[string, int]()},
+		"all pinned":       {WithCapacity[string, int](1)},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			var evicted []string
+			opts = append(opts, WithOnEvict(func(key string, _ int, _ EvictReason) {
+				evicted = append(evicted, key)
+			}))
+			from, to := NewCache[string, int](), NewCache(opts...)
+			from.Set("a", 1)
+			to.SetItem("b", Item[int]{Value: 2, Pinned: true})
+			if Move(from, to, "a") {
+				t.Fatalf("Got true moving into a cache with no room but wanted false")
+			}
+			if v, ok := from.Get("a"); !ok || v != 1 {
+				t.Fatalf(errorString, v, 1)
+			}
+			if _, ok := to.Get("a"); ok || to.Len() != 1 {
+				t.Fatalf(errorString, to.ToMap(), map[string]int{"b": 2})
+			}
+			if len(evicted) != 0 {
+				t.Fatalf(errorString, evicted, []string{})
+			}
+		})
+	}
+}
+
+func TestMoveConcurrent(t *testing.T) {
+	a, b := NewCache[string, int](), NewCache[string, int]()
+	a.Set("k", 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Move(a, b, "k")
+		}()
+		go func() {
+			defer wg.Done()
+			Move(b, a, "k")
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Moves in opposite directions deadlocked")
+	}
+	if n := a.Len() + b.Len(); n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+}
@@ -0,0 +1,34 @@
+package cubby
+
+// expireBuffer is the number of expired items ExpireNotify buffers before
+// dropping new ones.
+const expireBuffer = 64
+
+// ExpireNotify returns a channel on which items removed because they expired
+// are sent. Every call returns the same channel. Sends never block a sweep: if
+// the channel's buffer is full because no one is consuming, expired items are
+// dropped instead.
+func (c *Cache[K, V]) ExpireNotify() <-chan Item[V] {
+	c.lock()
+	defer c.mu.Unlock()
+	if c.expired == nil {
+		c.expired = make(chan Item[V], expireBuffer)
+	}
+	return c.expired
+}
+
+// emitExpired sends the expired items among evicted on ch without blocking.
+func emitExpired[K comparable, V any](ch chan Item[V], evicted []eviction[K, V]) {
+	if ch == nil {
+		return
+	}
+	for _, ev := range evicted {
+		if ev.reason != EvictExpired {
+			continue
+		}
+		select {
+		case ch <- ev.item:
+		default:
+		}
+	}
+}
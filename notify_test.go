@@ -0,0 +1,48 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestExpireNotify(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	ch := cache.ExpireNotify()
+	if ch != cache.ExpireNotify() {
+		t.Fatalf("Wanted ExpireNotify to return the same channel")
+	}
+	cache.SetToExpire("x", 1, 1*time.Second)
+	cache.Set("y", 2)
+	clock.Advance(1 * time.Minute)
+	cache.ClearExpired()
+	select {
+	case item := <-ch:
+		if item.Value != 1 {
+			t.Fatalf(errorString, item.Value, 1)
+		}
+	default:
+		t.Fatalf("Wanted an expired item but got none")
+	}
+	select {
+	case item := <-ch:
+		t.Fatalf("Got unexpected expired item %v", item)
+	default:
+	}
+}
+
+func TestExpireNotifyDoesNotBlock(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[int, int](clock))
+	ch := cache.ExpireNotify()
+	for i := 0; i < 2*expireBuffer; i++ {
+		cache.SetToExpire(i, i, 1*time.Second)
+	}
+	clock.Advance(1 * time.Minute)
+	cache.ClearExpired() // must return although nothing consumes ch
+	if len(ch) != expireBuffer {
+		t.Fatalf(errorString, len(ch), expireBuffer)
+	}
+}
@@ -0,0 +1,160 @@
+package cubby
+
+import "time"
+
+// Integer is the set of integer types that the numeric helpers accept.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Number is the set of integer and floating-point types that the aggregate
+// helpers accept.
+type Number interface {
+	Integer | ~float32 | ~float64
+}
+
+// Increment adds delta to the item value mapped to key and returns the
+// result. A missing or expired key is treated as zero and its new item is
+// added as by Set. Like Go's integer arithmetic, the result wraps around on
+// overflow; use SaturatingIncrement to clamp it instead.
+func Increment[K comparable, V Integer](c *Cache[K, V], key K, delta V) V {
+	return c.modify(key, func(v V) V {
+		return v + delta
+	})
+}
+
+// IncrementWithTTL adds delta to the item value mapped to key and returns the
+// result. If key is missing or expired, the new item expires after ttl; later
+// increments leave its expiration date unchanged. It is the building block of
+// a fixed-window rate limiter: the count resets only once the window that
+// began with the first increment has passed.
+func IncrementWithTTL[K comparable, V Integer](c *Cache[K, V], key K, delta V, ttl time.Duration) V {
+	return c.modifyOr(key, func(v V) V {
+		return v + delta
+	}, func(v V) Item[V] {
+		now := c.clock.Now()
+		return Item[V]{Value: v, CreatedAt: now, ExpiredAt: now.Add(c.scaled(ttl))}
+	})
+}
+
+// Decrement subtracts delta from the item value mapped to key and returns the
+// result. It otherwise behaves like Increment, including wrapping around on
+// overflow; use DecrementFloor to bound it instead.
+func Decrement[K comparable, V Integer](c *Cache[K, V], key K, delta V) V {
+	return c.modify(key, func(v V) V {
+		return v - delta
+	})
+}
+
+// SaturatingIncrement adds delta to the item value mapped to key and returns
+// the result, clamped to the minimum and maximum values of V rather than
+// wrapping around. It otherwise behaves like Increment.
+func SaturatingIncrement[K comparable, V Integer](c *Cache[K, V], key K, delta V) V {
+	return c.modify(key, func(v V) V {
+		lo, hi := limits[V]()
+		sum := v + delta
+		switch {
+		case delta > 0 && sum < v:
+			return hi
+		case delta < 0 && sum > v:
+			return lo
+		}
+		return sum
+	})
+}
+
+// DecrementFloor subtracts delta from the item value mapped to key and
+// returns the result, which is never less than floor, even if the
+// subtraction would wrap around. It otherwise behaves like Decrement.
+func DecrementFloor[K comparable, V Integer](c *Cache[K, V], key K, delta, floor V) V {
+	return c.modify(key, func(v V) V {
+		diff := v - delta
+		if (delta > 0 && diff > v) || diff < floor {
+			return floor
+		}
+		return diff
+	})
+}
+
+// GetAndReset returns the item value mapped to key and sets it to zero in one
+// operation, so no update made between the read and the reset is lost. The
+// item keeps its dates. It suits draining counters periodically, such as to
+// emit metrics. A missing or expired key is left as is and reported as
+// missing, as is every key once the cache is closed.
+func GetAndReset[K comparable, V Number](c *Cache[K, V], key K) (V, bool) {
+	var zero V
+	c.lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok || e.item.expiredAt(c.clock.Now()) || c.closed {
+		return zero, false
+	}
+	v := e.item.Value
+	c.setValue(e, zero)
+	return v, true
+}
+
+// limits returns the minimum and maximum values of V.
+func limits[V Integer]() (lo, hi V) {
+	var zero V
+	hi = ^zero
+	if hi > 0 { // unsigned
+		return zero, hi
+	}
+	lo = hi // -1
+	for lo<<1 < lo {
+		lo <<= 1
+	}
+	return lo, ^lo
+}
+
+// Sum returns the sum of the values of all unexpired items in c.
+func Sum[K comparable, V Number](c *Cache[K, V]) V {
+	var sum V
+	c.eachLive(func(v V) {
+		sum += v
+	})
+	return sum
+}
+
+// Min returns the least value of all unexpired items in c, or false if there
+// are none.
+func Min[K comparable, V Number](c *Cache[K, V]) (V, bool) {
+	var least V
+	found := false
+	c.eachLive(func(v V) {
+		if !found || v < least {
+			least, found = v, true
+		}
+	})
+	return least, found
+}
+
+// Max returns the greatest value of all unexpired items in c, or false if
+// there are none.
+func Max[K comparable, V Number](c *Cache[K, V]) (V, bool) {
+	var greatest V
+	found := false
+	c.eachLive(func(v V) {
+		if !found || v > greatest {
+			greatest, found = v, true
+		}
+	})
+	return greatest, found
+}
+
+// Average returns the mean of the values of all unexpired items in c, or
+// false if there are none.
+func Average[K comparable, V Number](c *Cache[K, V]) (float64, bool) {
+	var sum float64
+	n := 0
+	c.eachLive(func(v V) {
+		sum += float64(v)
+		n++
+	})
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
@@ -0,0 +1,167 @@
+package cubby
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestIncrement(t *testing.T) {
+	cache := NewCache[string, int]()
+	if got := Increment(cache, "x", 2); got != 2 {
+		t.Fatalf(errorString, got, 2)
+	}
+	if got := Increment(cache, "x", 3); got != 5 {
+		t.Fatalf(errorString, got, 5)
+	}
+	if got := Decrement(cache, "x", 7); got != -2 {
+		t.Fatalf(errorString, got, -2)
+	}
+	cache.SetItem("y", Item[int]{Value: 9, CreatedAt: past, ExpiredAt: past})
+	if got := Increment(cache, "y", 1); got != 1 {
+		t.Fatalf(errorString, got, 1)
+	}
+}
+
+func TestIncrementWithTTL(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int64](clock))
+	steps := []struct {
+		advance    time.Duration
+		want       int64
+		wantExpiry time.Time
+	}{
+		{advance: 0, want: 1, wantExpiry: now.Add(time.Minute)},
+		{advance: 30 * time.Second, want: 2, wantExpiry: now.Add(time.Minute)},
+		{advance: 29 * time.Second, want: 3, wantExpiry: now.Add(time.Minute)},
+		{advance: time.Second, want: 1, wantExpiry: now.Add(2 * time.Minute)}, // new window
+	}
+	for i, s := range steps {
+		clock.Advance(s.advance)
+		if got := IncrementWithTTL(cache, "hits", 1, time.Minute); got != s.want {
+			t.Fatalf("%d:"+errorString, i, got, s.want)
+		}
+		if item, _ := cache.GetItem("hits"); !item.ExpiredAt.Equal(s.wantExpiry) {
+			t.Fatalf("%d:"+errorString, i, item.ExpiredAt, s.wantExpiry)
+		}
+	}
+}
+
+func TestIncrementWraps(t *testing.T) {
+	signed := NewCache[string, int8]()
+	signed.Set("x", math.MaxInt8)
+	if got := Increment(signed, "x", 1); got != math.MinInt8 {
+		t.Fatalf(errorString, got, math.MinInt8)
+	}
+	unsigned := NewCache[string, uint8]()
+	if got := Decrement(unsigned, "x", 1); got != math.MaxUint8 {
+		t.Fatalf(errorString, got, math.MaxUint8)
+	}
+}
+
+func TestSaturatingIncrement(t *testing.T) {
+	cases := map[string]struct {
+		start, delta, want int8
+	}{
+		"within":    {start: 1, delta: 2, want: 3},
+		"above max": {start: 120, delta: 10, want: math.MaxInt8},
+		"below min": {start: -120, delta: -10, want: math.MinInt8},
+	}
+	cache := NewCache[string, int8]()
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache.Set(name, c.start)
+			if got := SaturatingIncrement(cache, name, c.delta); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+	unsigned := NewCache[string, uint64]()
+	unsigned.Set("x", math.MaxUint64-1)
+	if got := SaturatingIncrement(unsigned, "x", 5); got != math.MaxUint64 {
+		t.Fatalf(errorString, got, uint64(math.MaxUint64))
+	}
+}
+
+func TestDecrementFloor(t *testing.T) {
+	cases := map[string]struct {
+		start, delta, floor, want uint
+	}{
+		"above floor": {start: 5, delta: 2, floor: 0, want: 3},
+		"to floor":    {start: 5, delta: 5, floor: 0, want: 0},
+		"wraps":       {start: 1, delta: 5, floor: 0, want: 0},
+		"below floor": {start: 5, delta: 4, floor: 2, want: 2},
+	}
+	cache := NewCache[string, uint]()
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache.Set(name, c.start)
+			if got := DecrementFloor(cache, name, c.delta, c.floor); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestGetAndReset(t *testing.T) {
+	cache := NewCache[string, float64]()
+	cache.Set("x", 2.5)
+	cache.SetItem("old", Item[float64]{Value: 1, CreatedAt: past, ExpiredAt: past})
+	cases := []struct {
+		key    string
+		want   float64
+		wantOK bool
+	}{
+		{key: "x", want: 2.5, wantOK: true},
+		{key: "x", want: 0, wantOK: true}, // reset by the first call
+		{key: "old", want: 0, wantOK: false},
+		{key: "missing", want: 0, wantOK: false},
+	}
+	for _, c := range cases {
+		got, ok := GetAndReset(cache, c.key)
+		if got != c.want || ok != c.wantOK {
+			t.Fatalf("%s:"+errorString, c.key, []any{got, ok}, []any{c.want, c.wantOK})
+		}
+	}
+	if _, ok := cache.Get("missing"); ok {
+		t.Fatalf("Wanted missing key to stay missing but it was added")
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	cache := NewCache[string, float64]()
+	cache.Set("x", 1.5)
+	cache.Set("y", -2)
+	cache.Set("z", 4.5)
+	cache.SetItem("w", Item[float64]{Value: 100, CreatedAt: past, ExpiredAt: past})
+	if got := Sum(cache); got != 4 {
+		t.Fatalf(errorString, got, 4)
+	}
+	if got, ok := Min(cache); !ok || got != -2 {
+		t.Fatalf(errorString, got, -2)
+	}
+	if got, ok := Max(cache); !ok || got != 4.5 {
+		t.Fatalf(errorString, got, 4.5)
+	}
+	if got, ok := Average(cache); !ok || got != 4.0/3 {
+		t.Fatalf(errorString, got, 4.0/3)
+	}
+}
+
+func TestAggregatesEmpty(t *testing.T) {
+	cache := NewCache[string, int]()
+	if got := Sum(cache); got != 0 {
+		t.Fatalf(errorString, got, 0)
+	}
+	if _, ok := Min(cache); ok {
+		t.Fatalf("Got a minimum of an empty cache")
+	}
+	if _, ok := Max(cache); ok {
+		t.Fatalf("Got a maximum of an empty cache")
+	}
+	if _, ok := Average(cache); ok {
+		t.Fatalf("Got an average of an empty cache")
+	}
+}
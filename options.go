@@ -0,0 +1,362 @@
+package cubby
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// Option configures a Cache created by NewCache or NewTickingCache.
+type Option[K comparable, V any] func(*Cache[K, V])
+
+// WithTTL sets a default lifetime for items added with Set. Items added with
+// SetToExpire or SetItem are unaffected. A non-positive d means Set items
+// never expire, which is the default.
+func WithTTL[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.ttl = d
+	}
+}
+
+// WithTTLFunc sets a function that gives the lifetime of each item added with
+// Set from its value. A non-positive lifetime means the item never expires.
+// It takes precedence over WithTTL.
+func WithTTLFunc[K comparable, V any](fn func(V) time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.ttlFunc = fn
+	}
+}
+
+// WithSlidingTTL makes items added with Set expire after idle and extends
+// the expiration date of an unexpired item to idle after each read by Get or
+// GetItem, so items expire once they go unread for idle. If maxLifetime is
+// positive, no read extends an item past maxLifetime after its CreatedAt
+// date, so even an item read constantly expires eventually. The expiration
+// date an item is given is the earlier of the two deadlines, so IsExpired
+// and ClearExpired account for both. Reads never shorten an item's lifetime.
+// It takes precedence over WithTTL and WithTTLFunc.
+func WithSlidingTTL[K comparable, V any](idle, maxLifetime time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.slidingTTL = idle
+		c.maxLifetime = maxLifetime
+	}
+}
+
+// WithTier declares a TTL tier named name whose items expire after lifetime.
+// Items are put in a tier with SetInTier. A cache may have many tiers.
+func WithTier[K comparable, V any](name string, lifetime time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		if c.tiers == nil {
+			c.tiers = make(map[string]time.Duration)
+		}
+		c.tiers[name] = lifetime
+	}
+}
+
+// WithoutExpiration makes the cache a plain concurrent map whose items never
+// expire: every item is stored without an expiration date, including those
+// set with SetToExpire, SetItem, or a TTL option, so Get skips expiration
+// checks and ClearExpired returns at once without locking the cache.
+func WithoutExpiration[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.noExpiration = true
+	}
+}
+
+// WithLazyExpiration makes Get and GetItem remove expired items they find and
+// report them as missing, rather than return them until ClearExpired is
+// called. GetStale still returns expired item values.
+func WithLazyExpiration[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.lazyExpiration = true
+	}
+}
+
+// WithCapacity bounds the cache to n items. When a new key is added to a full
+// cache, the least recently used item is evicted to make room. A non-positive
+// n means the cache is unbounded, which is the default.
+func WithCapacity[K comparable, V any](n int) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.capacity = n
+	}
+}
+
+// WithRejectWhenFull makes a cache created WithCapacity refuse new keys once
+// it is full, rather than evict the least recently used item to make room.
+// Updates to keys already present still succeed. Refused items are not
+// stored and not passed to the OnEvict callback; Set drops them silently,
+// TrySet returns ErrCacheFull, and Stats counts them as Rejected. Expired
+// items count toward the capacity until they are removed.
+func WithRejectWhenFull[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.rejectWhenFull = true
+	}
+}
+
+// WithSoftLimit makes the lifetimes of expiring items shrink as the cache
+// grows past n items, so they are removed sooner when the cache is crowded.
+// Once the cache holds more than n items, every new lifetime, such as one
+// given by Set or SetToExpire, is scaled by n divided by the number of items.
+// Items that keep their dates, such as those set with SetItem, moved, or
+// loaded, are not. Stats reports the current factor. Unlike WithCapacity, no
+// items are evicted.
+func WithSoftLimit[K comparable, V any](n int) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.softLimit = n
+	}
+}
+
+// WithMaxCost bounds the total cost of the items in the cache to n. Items
+// added with SetWithCost carry the given cost and all other items cost 1.
+// When an item would push the total over n, least recently used items are
+// evicted until it fits. An item whose cost alone exceeds n is not stored. A
+// non-positive n means the cost is unbounded, which is the default.
+func WithMaxCost[K comparable, V any](n int64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.maxCost = n
+	}
+}
+
+// WithMaxLoadWait bounds how long GetOrCompute waits on another goroutine's
+// in-flight load of the same key to d. When d elapses, GetOrCompute returns
+// ErrLoadTimeout, or calls its loader itself if the cache was also created
+// WithLoadFallback. A non-positive d means waiting indefinitely, which is the
+// default.
+func WithMaxLoadWait[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.maxLoadWait = d
+	}
+}
+
+// WithLoadFallback makes GetOrCompute call its loader itself, rather than
+// return ErrLoadTimeout, when it gives up waiting on another goroutine's load.
+// It has no effect without WithMaxLoadWait.
+func WithLoadFallback[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.loadFallback = true
+	}
+}
+
+// WithGroup makes the cache deduplicate GetOrCompute loads through g, which
+// other caches may share, so a load of a key in flight for one of them is
+// awaited by the others instead of repeated. Each cache waiting on another's
+// load stores the loaded item as that cache's loader gave it, dates
+// included. By default, each cache has a Group of its own.
+func WithGroup[K comparable, V any](g *Group[K, V]) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.group = g
+	}
+}
+
+// WithMaxLoaders limits the number of loaders GetOrCompute runs at once, across
+// all keys, to n. Further loads wait until a running one returns. A
+// non-positive n means no limit, which is the default.
+func WithMaxLoaders[K comparable, V any](n int) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		if n > 0 {
+			c.loaders = make(chan struct{}, n)
+		} else {
+			c.loaders = nil
+		}
+	}
+}
+
+// WithLoadRetry makes GetOrCompute retry a loader that returns an error up
+// to retries more times before returning the error. Before retry attempt n,
+// counting from 1, it sleeps for backoff(n), such as a backoff returned by
+// ExponentialBackoff. A nil backoff retries immediately.
+func WithLoadRetry[K comparable, V any](retries int, backoff func(attempt int) time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		if backoff == nil {
+			backoff = func(int) time.Duration { return 0 }
+		}
+		c.loadRetries, c.loadBackoff = retries, backoff
+	}
+}
+
+// WithNegativeCaching makes GetOrCompute remember the error of a failed load
+// for d, returning it for the key without calling a loader until d elapses.
+// This keeps a failing backend from being called again at once. A
+// non-positive d disables it, which is the default.
+func WithNegativeCaching[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.negativeTTL = d
+	}
+}
+
+// WithLoadTiming makes the cache record how long the loaders called by
+// GetOrCompute take, reported by Stats as a count and percentiles.
+func WithLoadTiming[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.loadTimes = &loadTimes{}
+	}
+}
+
+// WithStaleReadTracking makes the cache count reads of items that expired
+// within grace of being read, and, if the cache was created
+// WithLazyExpiration, reads that remove an expired item. The count is
+// reported as Stats.StaleReads; a rising count suggests lifetimes are too
+// short for how items are read. It has no effect if grace is not positive.
+func WithStaleReadTracking[K comparable, V any](grace time.Duration) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.staleGrace = grace
+	}
+}
+
+// WithContentionStats makes the cache count how often taking its lock had to
+// wait for another goroutine, reported as Stats.LockWaits and
+// Stats.RLockWaits. Each acquisition first tries the lock without waiting, so
+// counting adds a little overhead.
+func WithContentionStats[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.contention = true
+	}
+}
+
+// WithWeigher sets the function MemoryEstimate uses to weigh each item's
+// value in bytes, in place of the value's Size method if it is a Sizer.
+func WithWeigher[K comparable, V any](fn func(key K, value V) int64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.weigher = fn
+	}
+}
+
+// WithClock sets the Clock used to timestamp items and check expiration. The
+// default clock reports time now in UTC.
+func WithClock[K comparable, V any](clock Clock) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.clock = clock
+	}
+}
+
+// WithLocalTime makes the cache timestamp items in local time rather than
+// UTC, so that their CreatedAt and ExpiredAt dates read as local wall-clock
+// times. Expiration compares instants, so it is unaffected. It replaces any
+// Clock set by WithClock.
+func WithLocalTime[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.clock = localClock{}
+	}
+}
+
+// WithLogger makes the cache log significant events to logger: evictions at
+// debug level with their key and reason, sweeps that remove items at info
+// level, and loader errors at error level. Without a logger, the cache does
+// no logging work.
+func WithLogger[K comparable, V any](logger *slog.Logger) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.logger = logger
+	}
+}
+
+// WithOnEvict sets a callback that is called with the key, value, and reason
+// of every item removed from the cache. Updates to an existing key are not
+// evictions. The callback is called after the cache is unlocked, so it may
+// safely use the cache.
+func WithOnEvict[K comparable, V any](fn func(key K, value V, reason EvictReason)) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onEvict = func(_ context.Context, key K, value V, reason EvictReason) {
+			fn(key, value, reason)
+		}
+	}
+}
+
+// WithOnEvictContext is like WithOnEvict but the callback also receives the
+// context of the operation that removed the item: the one passed to
+// SetContext or DeleteContext, or a background context for every other
+// operation, including sweeps. It replaces any callback set WithOnEvict.
+func WithOnEvictContext[K comparable, V any](fn func(ctx context.Context, key K, value V, reason EvictReason)) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onEvict = fn
+	}
+}
+
+// WithOnLoad sets a callback that is called with the key and item of every
+// entry read from a snapshot by Load, GobDecode, or LoadBinary, before it is
+// stored, so that values can be rehydrated, such as by reopening a
+// connection they refer to. Changes fn makes to the item are stored. It is
+// called while holding the write lock, so no reader sees an entry before fn
+// has seen it, and fn must not call methods of the cache, or it will
+// deadlock.
+func WithOnLoad[K comparable, V any](fn func(key K, item *Item[V])) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onLoad = fn
+	}
+}
+
+// WithMaxSubscribers limits the number of open subscriptions made with
+// Subscribe to n, beyond which Subscribe returns ErrTooManySubscribers. A
+// non-positive n means no limit, which is the default.
+func WithMaxSubscribers[K comparable, V any](n int) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.subs.max = n
+	}
+}
+
+// WithOnBeforeEvict sets a callback that is asked before each item is
+// evicted to keep the cache within its capacity or maximum cost. Returning
+// false vetoes the eviction of that item, and the next least recently used
+// item is asked instead. If every candidate is vetoed, the least recently
+// used one is evicted anyway, so the cache stays bounded. The callback is
+// called while holding the write lock, so it must not call methods of the
+// cache, or it will deadlock.
+func WithOnBeforeEvict[K comparable, V any](fn func(key K, item Item[V]) bool) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onBeforeEvict = fn
+	}
+}
+
+// WithOnSweep sets a callback that is called once per ClearExpired with all
+// the items it removed, if any. Like WithOnEvict, it is called after the cache
+// is unlocked. Both callbacks are called if both are set.
+func WithOnSweep[K comparable, V any](fn func(expired map[K]Item[V])) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.onSweep = fn
+	}
+}
+
+// WithHasher sets the function Fingerprint hashes values with, for values
+// whose default formatting does not reflect their contents.
+func WithHasher[K comparable, V any](fn func(V) uint64) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.hasher = fn
+	}
+}
+
+// WithRand sets the source of randomness Sample and RandomKey choose items
+// with, in place of the global source of math/rand, so that tests can make
+// their choices repeatable.
+func WithRand[K comparable, V any](src rand.Source) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.rand = rand.New(src)
+	}
+}
+
+// WithKeyCodec sets the functions that convert keys to and from the strings
+// that represent them in snapshots written by Save and SaveBinary. Keys that
+// are strings, integers, or implement encoding.TextMarshaler need no codec;
+// this is for other key types, such as structs from packages that cannot be
+// changed. A decode error makes Load fail with ErrCorruptSnapshot.
+func WithKeyCodec[K comparable, V any](encode func(K) (string, error), decode func(string) (K, error)) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.keyCodec = &keyCodec[K]{encode, decode}
+	}
+}
+
+// WithFinalizer makes the cache call OnEvict with EvictCleared for its
+// remaining items once it becomes unreachable, with the caveats of
+// runtime.SetFinalizer. A TickingCache is also stopped, unless one of its
+// jobs refers to it. Prefer calling Clear, or Stop, explicitly.
+func WithFinalizer[K comparable, V any]() Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.finalize = true
+	}
+}
+
+// WithName sets a name to identify the cache, e.g. in logs or metrics.
+func WithName[K comparable, V any](name string) Option[K, V] {
+	return func(c *Cache[K, V]) {
+		c.name = name
+	}
+}
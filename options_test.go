@@ -0,0 +1,416 @@
+package cubby
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestNewCacheWithoutOptions(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	item, ok := cache.GetItem("x")
+	if !ok {
+		t.Fatalf("Wanted key x to be in cache but it was not")
+	}
+	if !item.ExpiredAt.IsZero() {
+		t.Fatalf(errorString, item.ExpiredAt, time.Time{})
+	}
+}
+
+func TestWithTTL(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithTTL[string, int](1*time.Minute),
+		WithClock[string, int](clock),
+	)
+	cache.Set("x", 1)
+	item, _ := cache.GetItem("x")
+	if want := now.Add(1 * time.Minute); item.ExpiredAt != want {
+		t.Fatalf(errorString, item.ExpiredAt, want)
+	}
+	cache.SetToExpire("y", 2, 1*time.Hour)
+	item, _ = cache.GetItem("y")
+	if want := now.Add(1 * time.Hour); item.ExpiredAt != want {
+		t.Fatalf(errorString, item.ExpiredAt, want)
+	}
+}
+
+func TestWithTTLFunc(t *testing.T) {
+	type config struct {
+		validity time.Duration
+	}
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithTTL[string, config](1*time.Hour),
+		WithTTLFunc[string, config](func(c config) time.Duration {
+			return c.validity
+		}),
+		WithClock[string, config](clock),
+	)
+	cases := map[string]struct {
+		validity time.Duration
+		want     time.Time
+	}{
+		"validity":    {validity: 1 * time.Minute, want: now.Add(1 * time.Minute)},
+		"no validity": {validity: 0, want: time.Time{}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache.Set(name, config{validity: c.validity})
+			item, _ := cache.GetItem(name)
+			if item.ExpiredAt != c.want {
+				t.Fatalf(errorString, item.ExpiredAt, c.want)
+			}
+		})
+	}
+}
+
+func TestWithoutExpiration(t *testing.T) {
+	cache := NewCache(
+		WithoutExpiration[string, int](),
+		WithTTL[string, int](time.Nanosecond),
+		WithLazyExpiration[string, int](),
+	)
+	cache.Set("ttl", 1)
+	cache.SetToExpire("lifetime", 2, -time.Hour)
+	cache.SetItem("item", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+	cache.Set("touched", 4)
+	if v, ok := cache.GetAndTouch("touched", -time.Hour); !ok || v != 4 {
+		t.Fatalf(errorString, v, 4)
+	}
+	cache.ClearExpired()
+	if got := cache.ExpiredKeys(); len(got) != 0 {
+		t.Fatalf(errorString, got, []string{})
+	}
+	for _, k := range []string{"ttl", "lifetime", "item", "touched"} {
+		item, ok := cache.GetItem(k)
+		if !ok || !item.ExpiredAt.IsZero() {
+			t.Fatalf("%s:"+errorString, k, item, "an item that never expires")
+		}
+	}
+}
+
+func TestWithLazyExpiration(t *testing.T) {
+	var evicted []EvictReason
+	cache := NewCache(
+		WithLazyExpiration[string, int](),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			evicted = append(evicted, reason)
+		}),
+	)
+	cache.SetItem("x", Item[int]{Value: 1, CreatedAt: past, ExpiredAt: past})
+	cache.Set("y", 2)
+	if _, ok := cache.Get("x"); ok {
+		t.Fatalf("Got value for x but x should be expired.")
+	}
+	if cache.Len() != 1 {
+		t.Fatalf(errorString, cache.Len(), 1)
+	}
+	if len(evicted) != 1 || evicted[0] != EvictExpired {
+		t.Fatalf(errorString, evicted, []EvictReason{EvictExpired})
+	}
+	if v, ok := cache.Get("y"); !ok || v != 2 {
+		t.Fatalf(errorString, v, 2)
+	}
+}
+
+func TestWithOnBeforeEvict(t *testing.T) {
+	cases := map[string]struct {
+		veto    map[string]bool
+		evicted string
+		asked   []string
+	}{
+		"allowed":      {evicted: "x", asked: []string{"x"}},
+		"vetoed once":  {veto: map[string]bool{"x": true}, evicted: "y", asked: []string{"x", "y"}},
+		"all vetoed":   {veto: map[string]bool{"x": true, "y": true}, evicted: "x", asked: []string{"x", "y"}},
+		"other vetoed": {veto: map[string]bool{"y": true}, evicted: "x", asked: []string{"x"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var asked []string
+			cache := NewCache(
+				WithCapacity[string, int](2),
+				WithOnBeforeEvict(func(key string, _ Item[int]) bool {
+					asked = append(asked, key)
+					return !c.veto[key]
+				}),
+			)
+			cache.Set("x", 1)
+			cache.Set("y", 2)
+			cache.Set("z", 3)
+			if _, ok := cache.GetItem(c.evicted); ok {
+				t.Fatalf("Wanted %s to be evicted but it was not", c.evicted)
+			}
+			if cache.Len() != 2 {
+				t.Fatalf(errorString, cache.Len(), 2)
+			}
+			if !reflect.DeepEqual(asked, c.asked) {
+				t.Fatalf(errorString, asked, c.asked)
+			}
+		})
+	}
+}
+
+func TestWithCapacity(t *testing.T) {
+	cache := NewCache(WithCapacity[string, int](2))
+	cache.Set("x", 1)
+	cache.Set("y", 2)
+	cache.Get("x") // y is now the least recently used
+	cache.Set("z", 3)
+	if cache.Len() != 2 {
+		t.Fatalf(errorString, cache.Len(), 2)
+	}
+	if _, ok := cache.Get("y"); ok {
+		t.Fatalf("Wanted key y to be evicted but it was not")
+	}
+	for _, k := range []string{"x", "z"} {
+		if _, ok := cache.Get(k); !ok {
+			t.Fatalf("Wanted key %s to be in cache but it was not", k)
+		}
+	}
+	cache.Set("x", 4) // updates do not evict
+	if cache.Len() != 2 {
+		t.Fatalf(errorString, cache.Len(), 2)
+	}
+}
+
+func TestWithSoftLimit(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithSoftLimit[int, int](2),
+		WithClock[int, int](clock),
+	)
+	cases := []struct {
+		lifetime time.Duration
+		want     time.Duration
+		scale    float64
+	}{
+		{lifetime: 1 * time.Hour, want: 1 * time.Hour, scale: 1},
+		{lifetime: 1 * time.Hour, want: 1 * time.Hour, scale: 1},
+		{lifetime: 1 * time.Hour, want: 1 * time.Hour, scale: 2.0 / 3},
+		{lifetime: 1 * time.Hour, want: 40 * time.Minute, scale: 2.0 / 4},
+	}
+	for i, c := range cases {
+		cache.SetToExpire(i, i, c.lifetime)
+		item, _ := cache.GetItem(i)
+		if got := item.ExpiredAt.Sub(item.CreatedAt); got != c.want {
+			t.Fatalf(errorString, got, c.want)
+		}
+		if got := cache.Stats().TTLScale; got != c.scale {
+			t.Fatalf(errorString, got, c.scale)
+		}
+	}
+	cache.Set(9, 9) // items that never expire are unaffected
+	if item, _ := cache.GetItem(9); !item.ExpiredAt.IsZero() {
+		t.Fatalf(errorString, item.ExpiredAt, time.Time{})
+	}
+}
+
+func TestWithSoftLimitKeepsDates(t *testing.T) {
+	want := Item[int]{Value: 1, CreatedAt: now, ExpiredAt: now.Add(1 * time.Hour)}
+	cases := map[string]func(cache *Cache[string, int]){
+		"SetItem": func(cache *Cache[string, int]) {
+			cache.SetItem("x", want)
+		},
+		"Move": func(cache *Cache[string, int]) {
+			src := NewCache[string, int]()
+			src.SetItem("x", want)
+			Move(src, cache, "x")
+		},
+		"Merge": func(cache *Cache[string, int]) {
+			src := NewCache(WithClock[string, int](cubbytest.NewFakeClock(now)))
+			src.SetItem("x", want)
+			cache.Merge(src, MergeAll)
+		},
+		"Load": func(cache *Cache[string, int]) {
+			data, err := cache.encodeSnapshot(map[string]Item[int]{"x": want})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cache.Load(bytes.NewReader(data)); err != nil {
+				t.Fatal(err)
+			}
+		},
+	}
+	for name, write := range cases {
+		cache := NewCache(
+			WithSoftLimit[string, int](1),
+			WithClock[string, int](cubbytest.NewFakeClock(now)),
+		)
+		for _, k := range []string{"a", "b", "c"} {
+			cache.Set(k, 0)
+		}
+		write(cache)
+		if got, _ := cache.GetItem("x"); !got.ExpiredAt.Equal(want.ExpiredAt) {
+			t.Fatalf("%s:"+errorString, name, got.ExpiredAt, want.ExpiredAt)
+		}
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	cache.SetToExpire("x", 1, 1*time.Minute)
+	item, _ := cache.GetItem("x")
+	if item.CreatedAt != now {
+		t.Fatalf(errorString, item.CreatedAt, now)
+	}
+	cache.ClearExpired()
+	if cache.Len() != 1 {
+		t.Fatalf(errorString, cache.Len(), 1)
+	}
+	clock.Advance(2 * time.Minute)
+	cache.ClearExpired()
+	if cache.Len() != 0 {
+		t.Fatalf(errorString, cache.Len(), 0)
+	}
+}
+
+func TestWithLocalTime(t *testing.T) {
+	cases := map[string]struct {
+		opts []Option[string, int]
+		want *time.Location
+	}{
+		"default": {want: time.UTC},
+		"local":   {opts: []Option[string, int]{WithLocalTime[string, int]()}, want: time.Local},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(c.opts...)
+			cache.SetToExpire("x", 1, 1*time.Minute)
+			cache.SetToExpire("y", 2, -1*time.Minute)
+			x, _ := cache.GetItem("x")
+			if x.CreatedAt.Location() != c.want || x.ExpiredAt.Location() != c.want {
+				t.Fatalf(errorString, x.CreatedAt.Location(), c.want)
+			}
+			if x.IsExpired() {
+				t.Fatalf(errorString, true, false)
+			}
+			if y, _ := cache.GetItem("y"); !y.IsExpired() {
+				t.Fatalf(errorString, false, true)
+			}
+		})
+	}
+}
+
+func TestWithOnEvict(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	got := map[string]EvictReason{}
+	cache := NewCache(
+		WithCapacity[string, int](2),
+		WithClock[string, int](clock),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			got[key] = reason
+		}),
+	)
+	cache.Set("deleted", 1)
+	cache.Delete("deleted")
+	cache.SetToExpire("expired", 2, 1*time.Second)
+	clock.Advance(1 * time.Minute)
+	cache.ClearExpired()
+	cache.Set("capacity", 3)
+	cache.Set("cleared", 4)
+	cache.Set("other", 5)
+	cache.Clear()
+	want := map[string]EvictReason{
+		"deleted":  EvictDeleted,
+		"expired":  EvictExpired,
+		"capacity": EvictCapacity,
+		"cleared":  EvictCleared,
+		"other":    EvictCleared,
+	}
+	if len(got) != len(want) {
+		t.Fatalf(errorString, got, want)
+	}
+	for k, reason := range want {
+		if got[k] != reason {
+			t.Fatalf(errorString, got[k], reason)
+		}
+	}
+}
+
+func TestWithOnEvictContext(t *testing.T) {
+	type traceKey struct{}
+	traces := map[string]any{}
+	cache := NewCache(
+		WithCapacity[string, int](1),
+		WithOnEvictContext(func(ctx context.Context, key string, value int, reason EvictReason) {
+			traces[key] = ctx.Value(traceKey{})
+		}),
+	)
+	cache.Set("x", 1)
+	cache.SetContext(context.WithValue(context.Background(), traceKey{}, "set"), "y", 2)
+	cache.DeleteContext(context.WithValue(context.Background(), traceKey{}, "delete"), "y")
+	cache.Set("z", 3)
+	cache.Clear()
+	want := map[string]any{"x": "set", "y": "delete", "z": nil}
+	if !reflect.DeepEqual(traces, want) {
+		t.Fatalf(errorString, traces, want)
+	}
+}
+
+func TestWithOnSweep(t *testing.T) {
+	var sweeps []map[string]Item[int]
+	cache := NewCache(WithOnSweep(func(expired map[string]Item[int]) {
+		if len(expired) == 0 {
+			t.Errorf("Got an empty sweep")
+		}
+		sweeps = append(sweeps, expired)
+	}))
+	cache.Set("x", 1)
+	cache.SetItem("y", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: past})
+	cache.SetItem("z", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+	cache.ClearExpired()
+	cache.ClearExpired() // nothing to sweep
+	if len(sweeps) != 1 {
+		t.Fatalf(errorString, len(sweeps), 1)
+	}
+	if len(sweeps[0]) != 2 || sweeps[0]["y"].Value != 2 || sweeps[0]["z"].Value != 3 {
+		t.Fatalf(errorString, sweeps[0], "items y and z")
+	}
+}
+
+func TestWithFinalizer(t *testing.T) {
+	cases := map[string]struct {
+		opts []Option[string, int]
+		want int32
+	}{
+		"without": {},
+		"with":    {opts: []Option[string, int]{WithFinalizer[string, int]()}, want: 2},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var evicted atomic.Int32
+			func() {
+				cache := NewCache(append(c.opts, WithOnEvict(func(_ string, _ int, reason EvictReason) {
+					if reason == EvictCleared {
+						evicted.Add(1)
+					}
+				}))...)
+				cache.Set("x", 1)
+				cache.Set("y", 2)
+			}()
+			for i := 0; i < 20 && evicted.Load() < c.want; i++ {
+				runtime.GC()
+				time.Sleep(1 * time.Millisecond)
+			}
+			if got := evicted.Load(); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestWithName(t *testing.T) {
+	cache := NewCache(WithName[string, int]("sessions"))
+	if cache.Name() != "sessions" {
+		t.Fatalf(errorString, cache.Name(), "sessions")
+	}
+}
@@ -0,0 +1,193 @@
+package cubby
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrCorruptSnapshot is returned when a snapshot cannot be decoded,
+	// either because its contents are malformed or because its format
+	// version is not recognized.
+	ErrCorruptSnapshot = errors.New("cubby: corrupt snapshot")
+
+	// ErrUnsupportedVersion is returned along with ErrCorruptSnapshot when a
+	// snapshot was written in a format version this package cannot read.
+	ErrUnsupportedVersion = errors.New("cubby: unsupported snapshot version")
+)
+
+// snapshotMagic begins every snapshot so that other data is not mistaken for
+// one.
+const snapshotMagic = "cubby"
+
+// snapshotVersion is the version of the snapshot format written by Save. It
+// is the byte that follows snapshotMagic.
+const snapshotVersion byte = 1
+
+// Save writes a snapshot of the items in the cache to w. The snapshot is a
+// header of magic bytes and a format version followed by the items encoded
+// as JSON, so V must be encodable as JSON and K must be a valid JSON object
+// key type: a string, an integer, or a type implementing
+// encoding.TextMarshaler. Other keys need a codec set by WithKeyCodec.
+//
+// Like every snapshot, it is of a single point in time: the items are copied
+// with Items before they are encoded, so concurrent writes are either wholly
+// in the snapshot or not in it at all.
+func (c *Cache[K, V]) Save(w io.Writer) error {
+	data, err := c.GobEncode()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// SaveFunc is like Save but writes only the items for which pred returns
+// true, such as to persist long-lived items and drop transient ones. The
+// snapshot can be read with Load.
+func (c *Cache[K, V]) SaveFunc(w io.Writer, pred func(K, Item[V]) bool) error {
+	items := c.Items()
+	for k, item := range items {
+		if !pred(k, item) {
+			delete(items, k)
+		}
+	}
+	data, err := c.encodeSnapshot(items)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Load reads a snapshot written by Save from r and adds its items to the
+// cache, replacing the items of any keys already present. Errors reading from
+// r are returned as is; errors decoding the snapshot wrap ErrCorruptSnapshot.
+// If the cache is closed, nothing is added and it returns ErrClosed.
+func (c *Cache[K, V]) Load(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.GobDecode(data)
+}
+
+// GobEncode returns a snapshot of the items in the cache in the format written
+// by Save. It implements gob.GobEncoder.
+func (c *Cache[K, V]) GobEncode() ([]byte, error) {
+	return c.encodeSnapshot(c.Items())
+}
+
+// encodeSnapshot returns items in the format written by Save, with their keys
+// encoded by the cache's key codec if it has one.
+func (c *Cache[K, V]) encodeSnapshot(items map[K]Item[V]) ([]byte, error) {
+	var body any = items
+	if c.keyCodec != nil {
+		encoded := make(map[string]Item[V], len(items))
+		for k, item := range items {
+			s, err := c.keyCodec.encode(k)
+			if err != nil {
+				return nil, err
+			}
+			encoded[s] = item
+		}
+		body = encoded
+	}
+	var buf bytes.Buffer
+	buf.WriteString(snapshotMagic)
+	buf.WriteByte(snapshotVersion)
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode adds the items of a snapshot in the format written by Save to
+// the cache. It implements gob.GobDecoder. Gob decodes into a zero Cache,
+// such as the one it allocates for a *Cache field, which GobDecode first
+// prepares as NewCache would with no options.
+func (c *Cache[K, V]) GobDecode(data []byte) error {
+	c.initZero()
+	body, err := readHeader(data)
+	if err != nil {
+		return err
+	}
+	items, err := c.decodeSnapshot(body)
+	if err != nil {
+		return err
+	}
+	return c.insertLoaded(items)
+}
+
+// insertLoaded adds items decoded from a snapshot to the cache, passing each
+// to the cache's OnLoad callback first. It returns ErrClosed, adding nothing,
+// if the cache is closed.
+func (c *Cache[K, V]) insertLoaded(items map[K]Item[V]) error {
+	var evicted []eviction[K, V]
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	for k, item := range items {
+		if c.onLoad != nil {
+			c.onLoad(k, &item)
+		}
+		ev, _ := c.set(k, item, 1)
+		evicted = append(evicted, ev...)
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return nil
+}
+
+// decodeSnapshot decodes the items in the body of a snapshot, decoding their
+// keys with the cache's key codec if it has one.
+func (c *Cache[K, V]) decodeSnapshot(body []byte) (map[K]Item[V], error) {
+	if c.keyCodec == nil {
+		var items map[K]Item[V]
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+		}
+		return items, nil
+	}
+	var encoded map[string]Item[V]
+	if err := json.Unmarshal(body, &encoded); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+	items := make(map[K]Item[V], len(encoded))
+	for s, item := range encoded {
+		k, err := c.keyCodec.decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %v", ErrCorruptSnapshot, s, err)
+		}
+		items[k] = item
+	}
+	return items, nil
+}
+
+// keyCodec converts keys to and from the strings that represent them in
+// snapshots.
+type keyCodec[K any] struct {
+	encode func(K) (string, error)
+	decode func(string) (K, error)
+}
+
+// readHeader validates the header of a snapshot and returns the data that
+// follows it.
+func readHeader(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(snapshotMagic)) {
+		return nil, fmt.Errorf("%w: missing header", ErrCorruptSnapshot)
+	}
+	data = data[len(snapshotMagic):]
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: missing version", ErrCorruptSnapshot)
+	}
+	if v := data[0]; v != snapshotVersion {
+		return nil, fmt.Errorf("%w: %w %d", ErrCorruptSnapshot, ErrUnsupportedVersion, v)
+	}
+	return data[1:], nil
+}
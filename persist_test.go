@@ -0,0 +1,356 @@
+package cubby
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	created := time.Date(2023, 12, 1, 8, 30, 0, 0, time.UTC)
+	items := map[string]Item[int]{
+		"x": {Value: 1, CreatedAt: created},
+		"y": {Value: 2, CreatedAt: created, ExpiredAt: created.Add(1 * time.Hour)},
+	}
+	cache := NewCache[string, int]()
+	for k, item := range items {
+		cache.SetItem(k, item)
+	}
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	loaded := NewCache[string, int]()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if loaded.Len() != len(items) {
+		t.Fatalf(errorString, loaded.Len(), len(items))
+	}
+	for k, want := range items {
+		got, _ := loaded.GetItem(k)
+		if got.Value != want.Value ||
+			!got.CreatedAt.Equal(want.CreatedAt) ||
+			!got.ExpiredAt.Equal(want.ExpiredAt) {
+			t.Fatalf(errorString, got, want)
+		}
+	}
+}
+
+func TestSaveFunc(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.Set("y", 2)
+	cache.SetToExpire("z", 3, 1*time.Hour)
+	cases := map[string]struct {
+		pred func(string, Item[int]) bool
+		want []string
+	}{
+		"all":       {pred: func(string, Item[int]) bool { return true }, want: []string{"x", "y", "z"}},
+		"none":      {pred: func(string, Item[int]) bool { return false }, want: []string{}},
+		"by value":  {pred: func(_ string, i Item[int]) bool { return i.Value%2 == 1 }, want: []string{"x", "z"}},
+		"by expiry": {pred: func(_ string, i Item[int]) bool { return i.ExpiredAt.IsZero() }, want: []string{"x", "y"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := cache.SaveFunc(&buf, c.pred); err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			loaded := NewCache[string, int]()
+			if err := loaded.Load(&buf); err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			if loaded.Len() != len(c.want) {
+				t.Fatalf(errorString, loaded.Len(), len(c.want))
+			}
+			for _, k := range c.want {
+				if _, ok := loaded.GetItem(k); !ok {
+					t.Fatalf(errorString, ok, true)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveIsPointInTime(t *testing.T) {
+	formats := map[string]struct {
+		save func(*Cache[string, point], io.Writer) error
+		load func(*Cache[string, point], io.Reader) error
+	}{
+		"json":   {save: (*Cache[string, point]).Save, load: (*Cache[string, point]).Load},
+		"binary": {save: (*Cache[string, point]).SaveBinary, load: (*Cache[string, point]).LoadBinary},
+	}
+	for name, f := range formats {
+		t.Run(name, func(t *testing.T) {
+			// The writer keeps every key's value equal to one shared counter,
+			// so a torn snapshot would hold differing values.
+			cache := NewCache[string, point]()
+			keys := benchmarkKeys(100)
+			update := func(n int32) {
+				cache.Transact(func(tx *Tx[string, point]) {
+					for _, k := range keys {
+						tx.Set(k, point{n, -n})
+					}
+				})
+			}
+			update(0)
+			done := make(chan struct{})
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				for n := int32(1); ; n++ {
+					select {
+					case <-done:
+						return
+					default:
+						update(n)
+					}
+				}
+			}()
+			defer func() {
+				close(done)
+				<-stopped
+			}()
+			for i := 0; i < 20; i++ {
+				var buf bytes.Buffer
+				if err := f.save(cache, &buf); err != nil {
+					t.Fatalf(errorString, err, nil)
+				}
+				loaded := NewCache[string, point]()
+				if err := f.load(loaded, &buf); err != nil {
+					t.Fatalf(errorString, err, nil)
+				}
+				values := loaded.ToMap()
+				if len(values) != len(keys) {
+					t.Fatalf(errorString, len(values), len(keys))
+				}
+				want := values[keys[0]]
+				for k, v := range values {
+					if v != want {
+						t.Fatalf("key %s:"+errorString, k, v, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWithOnLoad(t *testing.T) {
+	formats := map[string]struct {
+		save func(*Cache[string, point], io.Writer) error
+		load func(*Cache[string, point], io.Reader) error
+	}{
+		"json":   {save: (*Cache[string, point]).Save, load: (*Cache[string, point]).Load},
+		"binary": {save: (*Cache[string, point]).SaveBinary, load: (*Cache[string, point]).LoadBinary},
+	}
+	for name, f := range formats {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, point]()
+			cache.Set("x", point{1, 1})
+			cache.Set("y", point{2, 2})
+			var buf bytes.Buffer
+			f.save(cache, &buf)
+			seen := map[string]bool{}
+			loaded := NewCache(WithOnLoad(func(key string, item *Item[point]) {
+				seen[key] = true
+				item.Value.Y *= 10
+			}))
+			if err := f.load(loaded, &buf); err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			if len(seen) != 2 {
+				t.Fatalf(errorString, seen, 2)
+			}
+			want := map[string]point{"x": {1, 10}, "y": {2, 20}}
+			if got := loaded.ToMap(); !reflect.DeepEqual(got, want) {
+				t.Fatalf(errorString, got, want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	snapshot := func(version byte, body string) []byte {
+		return append(append([]byte(snapshotMagic), version), body...)
+	}
+	cases := map[string]struct {
+		data    []byte
+		version bool
+	}{
+		"empty":           {data: []byte{}},
+		"missing magic":   {data: append([]byte{snapshotVersion}, "{}"...)},
+		"missing version": {data: []byte(snapshotMagic)},
+		"future version": {
+			data:    snapshot(snapshotVersion+1, "{}"),
+			version: true,
+		},
+		"malformed":  {data: snapshot(snapshotVersion, "{")},
+		"wrong type": {data: snapshot(snapshotVersion, `{"x":{"value":"one"}}`)},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			err := cache.Load(bytes.NewReader(c.data))
+			if !errors.Is(err, ErrCorruptSnapshot) {
+				t.Fatalf(errorString, err, ErrCorruptSnapshot)
+			}
+			if got := errors.Is(err, ErrUnsupportedVersion); got != c.version {
+				t.Fatalf(errorString, got, c.version)
+			}
+		})
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestLoadReadError(t *testing.T) {
+	cache := NewCache[string, int]()
+	err := cache.Load(failingReader{})
+	if err == nil || errors.Is(err, ErrCorruptSnapshot) {
+		t.Fatalf(errorString, err, "an I/O error")
+	}
+}
+
+// textKey is a structured key that encodes itself as text.
+type textKey struct {
+	Tenant string
+	ID     int
+}
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(k.Tenant + "/" + strconv.Itoa(k.ID)), nil
+}
+
+func (k *textKey) UnmarshalText(text []byte) error {
+	tenant, id, ok := strings.Cut(string(text), "/")
+	if !ok {
+		return errors.New("textKey: missing separator")
+	}
+	n, err := strconv.Atoi(id)
+	k.Tenant, k.ID = tenant, n
+	return err
+}
+
+// plainKey is a structured key with no text encoding of its own.
+type plainKey struct {
+	Tenant string
+	ID     int
+}
+
+func TestSaveAndLoadTextKeys(t *testing.T) {
+	cache := NewCache[textKey, int]()
+	cache.Set(textKey{"a", 1}, 1)
+	cache.Set(textKey{"b", 2}, 2)
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	loaded := NewCache[textKey, int]()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if got, want := loaded.ToMap(), cache.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+}
+
+func TestWithKeyCodec(t *testing.T) {
+	decode := func(s string) (plainKey, error) {
+		var k textKey
+		err := k.UnmarshalText([]byte(s))
+		return plainKey(k), err
+	}
+	codec := WithKeyCodec[plainKey, string](func(k plainKey) (string, error) {
+		b, err := textKey(k).MarshalText()
+		return string(b), err
+	}, decode)
+	badCodec := WithKeyCodec[plainKey, string](func(plainKey) (string, error) {
+		return "no separator", nil
+	}, decode)
+	formats := map[string]struct {
+		save func(*Cache[plainKey, string], io.Writer) error
+		load func(*Cache[plainKey, string], io.Reader) error
+	}{
+		"json":   {save: (*Cache[plainKey, string]).Save, load: (*Cache[plainKey, string]).Load},
+		"binary": {save: (*Cache[plainKey, string]).SaveBinary, load: (*Cache[plainKey, string]).LoadBinary},
+	}
+	for name, f := range formats {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(codec)
+			cache.Set(plainKey{"a", 1}, "x")
+			cache.Set(plainKey{"b", 2}, "y")
+			var buf bytes.Buffer
+			if err := f.save(cache, &buf); err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			loaded := NewCache(codec)
+			if err := f.load(loaded, &buf); err != nil {
+				t.Fatalf(errorString, err, nil)
+			}
+			if got, want := loaded.ToMap(), cache.ToMap(); !reflect.DeepEqual(got, want) {
+				t.Fatalf(errorString, got, want)
+			}
+			bad := NewCache(badCodec)
+			bad.Set(plainKey{"c", 3}, "z")
+			buf.Reset()
+			f.save(bad, &buf)
+			if err := f.load(NewCache(codec), &buf); !errors.Is(err, ErrCorruptSnapshot) {
+				t.Fatalf(errorString, err, ErrCorruptSnapshot)
+			}
+		})
+	}
+}
+
+func TestGob(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cache); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	decoded := NewCache[string, int]()
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if v, ok := decoded.Get("x"); !ok || v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+}
+
+func TestGobField(t *testing.T) {
+	type state struct {
+		Name  string
+		Cache *Cache[string, int]
+	}
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state{"s", cache}); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	var decoded state
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if v, ok := decoded.Cache.Get("x"); !ok || v != 1 {
+		t.Fatalf(errorString, v, 1)
+	}
+	decoded.Cache.Set("y", 2)
+	if _, err := decoded.Cache.GetOrCompute("z", func() (int, error) { return 3, nil }); err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	if got, want := decoded.Cache.ToMap(), map[string]int{"x": 1, "y": 2, "z": 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+}
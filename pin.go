@@ -0,0 +1,37 @@
+package cubby
+
+// Pin marks the item mapped to key as pinned, so it is never evicted to make
+// room in a bounded cache, and reports whether key was present. The item stays
+// pinned when key is updated until Unpin is called. It returns false, leaving
+// the item as is, if the cache is closed.
+func (c *Cache[K, V]) Pin(key K) bool {
+	return c.setPinned(key, true)
+}
+
+// Unpin undoes Pin for the item mapped to key and reports whether key was
+// present. Like Pin, it returns false if the cache is closed.
+func (c *Cache[K, V]) Unpin(key K) bool {
+	return c.setPinned(key, false)
+}
+
+// setPinned sets the Pinned field of the item mapped to key.
+func (c *Cache[K, V]) setPinned(key K, pinned bool) bool {
+	c.lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	e, ok := c.items[key]
+	if ok {
+		e.item.Pinned = pinned
+	}
+	return ok
+}
+
+// ClearUnpinned removes all items from the cache except pinned ones and
+// returns how many it removed.
+func (c *Cache[K, V]) ClearUnpinned() int {
+	return c.ClearExcept(func(_ K, item Item[V]) bool {
+		return item.Pinned
+	})
+}
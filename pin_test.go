@@ -0,0 +1,84 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestPin(t *testing.T) {
+	cache := NewCache(WithCapacity[string, int](2))
+	cache.Set("admin", 1)
+	if !cache.Pin("admin") {
+		t.Fatalf("Wanted Pin to find key admin but it did not")
+	}
+	if cache.Pin("missing") {
+		t.Fatalf("Wanted Pin not to find key missing but it did")
+	}
+	cache.Set("x", 2)
+	cache.Set("y", 3) // evicts x, although admin is least recently used
+	if _, ok := cache.Get("admin"); !ok {
+		t.Fatalf("Wanted pinned key admin to be in cache but it was not")
+	}
+	if _, ok := cache.Get("x"); ok {
+		t.Fatalf("Wanted key x to be evicted but it was not")
+	}
+	cache.Set("admin", 4) // updates keep the pin
+	if item, _ := cache.GetItem("admin"); !item.Pinned {
+		t.Fatalf("Wanted key admin to stay pinned after an update")
+	}
+	cache.Unpin("admin")
+	cache.Get("y")
+	cache.Set("z", 5)
+	if _, ok := cache.Get("admin"); ok {
+		t.Fatalf("Wanted unpinned key admin to be evicted but it was not")
+	}
+}
+
+func TestPinAllPinned(t *testing.T) {
+	var evicted []string
+	cache := NewCache(
+		WithCapacity[string, int](2),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			evicted = append(evicted, key)
+		}),
+	)
+	cache.SetItem("x", Item[int]{Value: 1, CreatedAt: now, Pinned: true})
+	cache.SetItem("y", Item[int]{Value: 2, CreatedAt: now, Pinned: true})
+	cache.Set("z", 3)
+	if cache.Len() != 2 {
+		t.Fatalf(errorString, cache.Len(), 2)
+	}
+	if _, ok := cache.Get("z"); ok {
+		t.Fatalf("Wanted key z to be dropped but it was not")
+	}
+	if len(evicted) != 1 || evicted[0] != "z" {
+		t.Fatalf(errorString, evicted, []string{"z"})
+	}
+}
+
+func TestPinExpired(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(WithClock[string, int](clock))
+	cache.SetToExpire("x", 1, 1*time.Second)
+	cache.Pin("x")
+	clock.Advance(1 * time.Minute)
+	cache.ClearExpired()
+	if cache.Len() != 0 {
+		t.Fatalf(errorString, cache.Len(), 0)
+	}
+}
+
+func TestClearUnpinned(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	cache.Set("y", 2)
+	cache.Pin("y")
+	if n := cache.ClearUnpinned(); n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+	if _, ok := cache.Get("y"); !ok || cache.Len() != 1 {
+		t.Fatalf("Wanted only pinned key y to remain but got %v", cache.ToMap())
+	}
+}
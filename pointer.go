@@ -0,0 +1,25 @@
+package cubby
+
+// PointerCache is a Cache that stores pointers to values of type V, so
+// retrieving an item copies a pointer rather than a possibly large value.
+//
+// The trade-off is aliasing: every pointer retrieved for a key refers to the
+// same value, so mutating it changes the cached value for every reader without
+// holding the cache's lock. Treat retrieved values as read-only and replace
+// them with Set or SetValue instead. Copies of the cache's contents, such as
+// those returned by Items, copy the pointers and so share the values too.
+type PointerCache[K comparable, V any] struct {
+	*Cache[K, *V]
+}
+
+// SetValue adds or updates a copy of value mapped to key in the cache. The
+// value is copied once, when it is set.
+func (pc *PointerCache[K, V]) SetValue(key K, value V) {
+	pc.Set(key, &value)
+}
+
+// NewPointerCache creates a PointerCache with K type keys and *V type values
+// configured by opts.
+func NewPointerCache[K comparable, V any](opts ...Option[K, *V]) *PointerCache[K, V] {
+	return &PointerCache[K, V]{Cache: NewCache(opts...)}
+}
@@ -0,0 +1,26 @@
+package cubby
+
+import "testing"
+
+func TestPointerCache(t *testing.T) {
+	type blob struct {
+		data [1024]byte
+	}
+	cache := NewPointerCache[string, blob]()
+	var b blob
+	b.data[0] = 1
+	cache.SetValue("x", b)
+	b.data[0] = 2 // the cache holds its own copy
+	p1, ok := cache.Get("x")
+	if !ok || p1.data[0] != 1 {
+		t.Fatalf(errorString, p1.data[0], 1)
+	}
+	p2, _ := cache.Get("x")
+	if p1 != p2 {
+		t.Fatalf("Wanted retrieved pointers to alias the cached value")
+	}
+	var s Store[string, *blob] = cache
+	if s.Len() != 1 {
+		t.Fatalf(errorString, s.Len(), 1)
+	}
+}
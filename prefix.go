@@ -0,0 +1,40 @@
+package cubby
+
+import "strings"
+
+// ByPrefix returns the unexpired items in c whose keys begin with prefix, such
+// as every item under "user:42:" in a cache of namespaced keys.
+func ByPrefix[V any](c *Cache[string, V], prefix string) map[string]Item[V] {
+	c.rlock()
+	defer c.mu.RUnlock()
+	now := c.clock.Now()
+	items := make(map[string]Item[V])
+	for k, e := range c.items {
+		if strings.HasPrefix(k, prefix) && !e.item.expiredAt(now) {
+			items[k] = e.item
+		}
+	}
+	return items
+}
+
+// DeleteByPrefix removes the items in c whose keys begin with prefix and
+// returns how many were removed. The cache's OnEvict callback is called for
+// each with EvictDeleted.
+func DeleteByPrefix[V any](c *Cache[string, V], prefix string) int {
+	var evicted []eviction[string, V]
+	n := 0
+	c.lock()
+	for k := range c.items {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		e, _ := c.remove(k)
+		n++
+		if c.observed() {
+			evicted = append(evicted, eviction[string, V]{k, e.item, EvictDeleted})
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return n
+}
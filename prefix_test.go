@@ -0,0 +1,80 @@
+package cubby
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestByPrefix(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("user:1:name", 1)
+	cache.Set("user:1:age", 2)
+	cache.Set("user:10:name", 3)
+	cache.Set("team:1:name", 4)
+	cache.SetItem("user:1:stale", Item[int]{Value: 5, CreatedAt: past, ExpiredAt: past.Add(1 * time.Second)})
+	cases := map[string]struct {
+		prefix string
+		want   []string
+	}{
+		"namespace": {prefix: "user:1:", want: []string{"user:1:age", "user:1:name"}},
+		"shorter":   {prefix: "user:1", want: []string{"user:10:name", "user:1:age", "user:1:name"}},
+		"empty":     {prefix: "", want: []string{"team:1:name", "user:10:name", "user:1:age", "user:1:name"}},
+		"no match":  {prefix: "org:", want: []string{}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := sortedKeys(ByPrefix(cache, c.prefix))
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+// sortedKeys returns the keys of items in ascending order.
+func sortedKeys[V any](items map[string]Item[V]) []string {
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestDeleteByPrefix(t *testing.T) {
+	cases := map[string]struct {
+		prefix string
+		want   int
+		left   []string
+	}{
+		"namespace": {prefix: "user:1:", want: 2, left: []string{"team:1:name", "user:10:name"}},
+		"shorter":   {prefix: "user:1", want: 3, left: []string{"team:1:name"}},
+		"empty":     {prefix: "", want: 4, left: []string{}},
+		"no match":  {prefix: "org:", want: 0, left: []string{"team:1:name", "user:10:name", "user:1:age", "user:1:name"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted []string
+			cache := NewCache[string, int](WithOnEvict(func(key string, _ int, reason EvictReason) {
+				if reason == EvictDeleted {
+					deleted = append(deleted, key)
+				}
+			}))
+			cache.Set("user:1:name", 1)
+			cache.Set("user:1:age", 2)
+			cache.Set("user:10:name", 3)
+			cache.Set("team:1:name", 4)
+			if got := DeleteByPrefix(cache, c.prefix); got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if len(deleted) != c.want {
+				t.Fatalf(errorString, len(deleted), c.want)
+			}
+			if got := sortedKeys(cache.Items()); !reflect.DeepEqual(got, c.left) {
+				t.Fatalf(errorString, got, c.left)
+			}
+		})
+	}
+}
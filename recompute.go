@@ -0,0 +1,83 @@
+package cubby
+
+import (
+	"errors"
+	"time"
+)
+
+// recipe rebuilds the value of a key evicted for capacity.
+type recipe[V any] struct {
+	fn        func() (V, error)
+	expiredAt time.Time // of the evicted item
+}
+
+// SetRecomputable is like Set but also keeps recompute as a recipe for the
+// value of key. If the item is later evicted for capacity, a Get of key calls
+// recompute, as GetOrCompute would, and stores the result instead of missing.
+// This suits large values that are costly to hold but cheap to rebuild.
+//
+// The recipe lasts only as long as key is set by SetRecomputable or rebuilt
+// from it: it is dropped when key is set by other means, deleted, or expires
+// and is removed, or the cache is cleared. The recipe of an evicted key is
+// kept until the key is next read, set, or deleted; if the evicted item had an
+// expiration date, ClearExpired also drops the recipe once that date passes.
+// A Get whose recompute fails reports key as missing.
+func (c *Cache[K, V]) SetRecomputable(key K, value V, recompute func() (V, error)) {
+	_ = c.setRecomputable(key, c.newItem(value), recompute)
+}
+
+// setRecomputable stores item and attaches fn to it as its recipe in one
+// operation. It returns ErrClosed if the cache is closed and ErrCacheFull if
+// it has no room for item.
+func (c *Cache[K, V]) setRecomputable(key K, item Item[V], fn func() (V, error)) error {
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	evicted, stored := c.set(key, item, 1)
+	if stored {
+		c.items[key].recipe = fn
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	if !stored {
+		return ErrCacheFull
+	}
+	return nil
+}
+
+// recompute rebuilds and stores the value of key from its recipe, if any,
+// keeping the recipe for the next eviction.
+func (c *Cache[K, V]) recompute(key K) (V, bool) {
+	c.rlock()
+	r, ok := c.recipes[key]
+	c.mu.RUnlock()
+	var zero V
+	if !ok {
+		return zero, false
+	}
+	item, err := c.getOrComputeItem(key, func() (Item[V], error) {
+		v, err := r.fn()
+		if err != nil {
+			return Item[V]{}, err
+		}
+		return c.newItem(v), nil
+	}, func(key K, item Item[V]) error {
+		return c.setRecomputable(key, item, r.fn)
+	})
+	if err != nil && !errors.Is(err, ErrCacheFull) {
+		return zero, false
+	}
+	return item.Value, true
+}
+
+// dropExpiredRecipes drops the recipes of evicted items that would have
+// expired by now. The caller must hold the write lock.
+func (c *Cache[K, V]) dropExpiredRecipes(now time.Time) {
+	for key, r := range c.recipes {
+		if expiredBy(r.expiredAt, now) {
+			delete(c.recipes, key)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cubby
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestSetRecomputable(t *testing.T) {
+	cases := map[string]struct {
+		drop  func(c *Cache[string, int])
+		err   error
+		want  int
+		ok    bool
+		calls int
+		kept  int // recipes of evicted keys left after the Get
+	}{
+		"evicted for capacity": {
+			drop: func(c *Cache[string, int]) { c.Set("y", 2); c.Set("z", 3) },
+			want: 10, ok: true, calls: 1,
+		},
+		"recompute fails": {
+			drop: func(c *Cache[string, int]) { c.Set("y", 2); c.Set("z", 3) },
+			err:  errors.New("failed"), calls: 1, kept: 1,
+		},
+		"still present": {
+			drop: func(c *Cache[string, int]) {},
+			want: 1, ok: true,
+		},
+		"deleted": {
+			drop: func(c *Cache[string, int]) { c.Delete("x") },
+		},
+		"cleared": {
+			drop: func(c *Cache[string, int]) { c.Clear() },
+		},
+		"set again before eviction": {
+			drop: func(c *Cache[string, int]) { c.Set("x", 5); c.Set("y", 2); c.Set("z", 3) },
+		},
+		"set again after eviction": {
+			drop: func(c *Cache[string, int]) { c.Set("y", 2); c.Set("z", 3); c.Set("x", 5) },
+			want: 5, ok: true,
+		},
+		"deleted after eviction": {
+			drop: func(c *Cache[string, int]) { c.Set("y", 2); c.Set("z", 3); c.Delete("x") },
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int](WithCapacity[string, int](2))
+			calls := 0
+			cache.SetRecomputable("x", 1, func() (int, error) {
+				calls++
+				return 10, c.err
+			})
+			c.drop(cache)
+			got, ok := cache.Get("x")
+			if got != c.want || ok != c.ok {
+				t.Fatalf(errorString, []any{got, ok}, []any{c.want, c.ok})
+			}
+			if calls != c.calls {
+				t.Fatalf(errorString, calls, c.calls)
+			}
+			if _, ok := cache.GetItem("x"); ok != c.ok {
+				t.Fatalf(errorString, ok, c.ok)
+			}
+			if len(cache.recipes) != c.kept {
+				t.Fatalf(errorString, len(cache.recipes), c.kept)
+			}
+		})
+	}
+}
+
+func TestRecipeLifetime(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithCapacity[string, int](1),
+		WithTTL[string, int](time.Minute),
+		WithClock[string, int](clock),
+	)
+	calls := 0
+	cache.SetRecomputable("x", 1, func() (int, error) {
+		calls++
+		return 10, nil
+	})
+	for i := 1; i <= 2; i++ {
+		cache.Set("y", 2) // evicts x
+		if got, ok := cache.Get("x"); !ok || got != 10 || calls != i {
+			t.Fatalf("recompute %d:"+errorString, i, []any{got, ok, calls}, []any{10, true, i})
+		}
+	}
+	cache.Set("y", 2)
+	cache.ClearExpired()
+	if len(cache.recipes) != 1 {
+		t.Fatalf(errorString, len(cache.recipes), 1)
+	}
+	clock.Advance(2 * time.Minute)
+	cache.ClearExpired()
+	if len(cache.recipes) != 0 {
+		t.Fatalf(errorString, len(cache.recipes), 0)
+	}
+	if _, ok := cache.Get("x"); ok || calls != 2 {
+		t.Fatalf(errorString, []any{ok, calls}, []any{false, 2})
+	}
+}
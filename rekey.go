@@ -0,0 +1,31 @@
+package cubby
+
+// Rekey creates a Cache, configured by opts, holding the items of c mapped to
+// new keys given by fn. Items keep their values and timestamps. If fn maps
+// several keys to the same new key, the item created last is kept; use
+// RekeyFunc to merge them instead.
+func Rekey[K1, K2 comparable, V any](c *Cache[K1, V], fn func(K1) K2, opts ...Option[K2, V]) *Cache[K2, V] {
+	return RekeyFunc(c, fn, func(a, b Item[V]) Item[V] {
+		if b.CreatedAt.After(a.CreatedAt) {
+			return b
+		}
+		return a
+	}, opts...)
+}
+
+// RekeyFunc is like Rekey but resolves keys that collide with merge, which is
+// given the item already mapped to the new key and the colliding item and
+// returns the item to keep.
+func RekeyFunc[K1, K2 comparable, V any](c *Cache[K1, V], fn func(K1) K2, merge func(a, b Item[V]) Item[V], opts ...Option[K2, V]) *Cache[K2, V] {
+	items := make(map[K2]Item[V])
+	for k, item := range c.Items() {
+		k2 := fn(k)
+		if prev, ok := items[k2]; ok {
+			item = merge(prev, item)
+		}
+		items[k2] = item
+	}
+	rekeyed := NewCache(opts...)
+	rekeyed.SwapAll(items)
+	return rekeyed
+}
@@ -0,0 +1,47 @@
+package cubby
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRekey(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.SetItem("user:1", Item[int]{Value: 1, CreatedAt: past})
+	cache.SetItem("USER:1", Item[int]{Value: 2, CreatedAt: now})
+	cache.SetItem("user:2", Item[int]{Value: 3, CreatedAt: now, ExpiredAt: future})
+	rekeyed := Rekey(cache, func(k string) string {
+		return strings.TrimPrefix(strings.ToLower(k), "user:")
+	})
+	if rekeyed.Len() != 2 {
+		t.Fatalf(errorString, rekeyed.Len(), 2)
+	}
+	if item, _ := rekeyed.GetItem("1"); item.Value != 2 {
+		t.Fatalf(errorString, item.Value, 2)
+	}
+	if item, _ := rekeyed.GetItem("2"); item.Value != 3 || item.ExpiredAt != future {
+		t.Fatalf(errorString, item, Item[int]{Value: 3, CreatedAt: now, ExpiredAt: future})
+	}
+}
+
+func TestRekeyFunc(t *testing.T) {
+	cache := NewCache[int, int]()
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+	}
+	sum := func(a, b Item[int]) Item[int] {
+		a.Value += b.Value
+		return a
+	}
+	parity := RekeyFunc(cache, func(k int) string {
+		return strconv.Itoa(k % 2)
+	}, sum, WithTTL[string, int](1*time.Hour))
+	if v, _ := parity.Get("0"); v != 20 {
+		t.Fatalf(errorString, v, 20)
+	}
+	if v, _ := parity.Get("1"); v != 25 {
+		t.Fatalf(errorString, v, 25)
+	}
+}
@@ -0,0 +1,78 @@
+package cubby
+
+import "math/rand"
+
+// Sample returns up to n randomly chosen unexpired items from the cache, each
+// as likely to be chosen as any other. It takes time proportional to the
+// number of items it draws, which is about n unless many items are expired.
+// If the cache was created WithRand, the same source chooses the same items
+// from a cache given the same keys in the same order.
+func (c *Cache[K, V]) Sample(n int) map[K]Item[V] {
+	c.rlock()
+	defer c.mu.RUnlock()
+	items := make(map[K]Item[V], max(min(n, len(c.order)), 0))
+	if n <= 0 {
+		return items
+	}
+	now := c.clock.Now()
+	c.draw(func(e *entry[K, V]) bool {
+		if !e.item.expiredAt(now) {
+			items[e.key] = e.item
+		}
+		return len(items) < n
+	})
+	return items
+}
+
+// RandomKey returns the key of a randomly chosen unexpired item in the cache,
+// or false if there are none. Like Sample, every key is as likely as any
+// other, and it draws items only until it finds an unexpired one.
+func (c *Cache[K, V]) RandomKey() (K, bool) {
+	c.rlock()
+	defer c.mu.RUnlock()
+	var key K
+	found := false
+	now := c.clock.Now()
+	c.draw(func(e *entry[K, V]) bool {
+		if e.item.expiredAt(now) {
+			return true
+		}
+		key, found = e.key, true
+		return false
+	})
+	return key, found
+}
+
+// draw calls fn with the entries of the cache in random order until fn
+// returns false or every entry has been drawn. It is a partial Fisher-Yates
+// shuffle of the cache's order that records its swaps in a map rather than
+// making them, so it takes time proportional to the number of entries drawn.
+// The caller must hold the read or the write lock.
+func (c *Cache[K, V]) draw(fn func(e *entry[K, V]) bool) {
+	swapped := make(map[int]int)
+	at := func(i int) int {
+		if j, ok := swapped[i]; ok {
+			return j
+		}
+		return i
+	}
+	for i := 0; i < len(c.order); i++ {
+		j := i + c.intn(len(c.order)-i)
+		pick := at(j)
+		swapped[j] = at(i)
+		if !fn(c.order[pick]) {
+			return
+		}
+	}
+}
+
+// intn returns a random int in [0, n) from the cache's source of randomness.
+// It is safe to call while holding the read lock.
+func (c *Cache[K, V]) intn(n int) int {
+	if c.rand == nil {
+		return rand.Intn(n)
+	}
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	return c.rand.Intn(n)
+}
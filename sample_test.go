@@ -0,0 +1,115 @@
+package cubby
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// seeded returns a cache of ints 0 to 9, plus expired ints 10 to 19, whose
+// random choices are made by a source seeded with seed.
+func seeded(seed int64) *Cache[int, int] {
+	cache := NewCache(WithRand[int, int](rand.NewSource(seed)))
+	for i := 0; i < 10; i++ {
+		cache.Set(i, i)
+	}
+	for i := 10; i < 20; i++ {
+		cache.SetItem(i, Item[int]{Value: i, CreatedAt: past, ExpiredAt: past})
+	}
+	return cache
+}
+
+// sampledKeys returns the keys of sample in order.
+func sampledKeys(sample map[int]Item[int]) []int {
+	keys := make([]int, 0, len(sample))
+	for k := range sample {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func TestSample(t *testing.T) {
+	cases := map[string]struct {
+		n    int
+		want int
+	}{
+		"negative": {n: -1, want: 0},
+		"none":     {n: 0, want: 0},
+		"some":     {n: 3, want: 3},
+		"all":      {n: 10, want: 10},
+		"extra":    {n: 50, want: 10},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			sample := seeded(1).Sample(c.n)
+			if len(sample) != c.want {
+				t.Fatalf(errorString, len(sample), c.want)
+			}
+			for k, item := range sample {
+				if k >= 10 || item.Value != k {
+					t.Fatalf("Got expired or mismatched item %v for key %v", item, k)
+				}
+			}
+			got, want := sampledKeys(seeded(1).Sample(c.n)), sampledKeys(sample)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Got a different sample from the same seed:"+errorString, got, want)
+			}
+		})
+	}
+}
+
+func TestSampleCoversEveryKey(t *testing.T) {
+	seen := map[int]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		for k := range seeded(seed).Sample(3) {
+			seen[k] = true
+		}
+	}
+	if len(seen) != 10 {
+		t.Fatalf(errorString, len(seen), 10)
+	}
+}
+
+func TestRandomKey(t *testing.T) {
+	cache := NewCache(WithRand[int, int](rand.NewSource(1)))
+	if _, ok := cache.RandomKey(); ok {
+		t.Fatalf("Got a random key from an empty cache")
+	}
+	cache.SetItem(0, Item[int]{Value: 0, CreatedAt: past, ExpiredAt: past})
+	if _, ok := cache.RandomKey(); ok {
+		t.Fatalf("Got a random key from a cache of expired items")
+	}
+	seen := map[int]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		k, ok := seeded(seed).RandomKey()
+		if !ok || k >= 10 {
+			t.Fatalf("Got expired or missing key %v", k)
+		}
+		if again, _ := seeded(seed).RandomKey(); again != k {
+			t.Fatalf("Got a different key from the same seed:"+errorString, again, k)
+		}
+		seen[k] = true
+	}
+	if len(seen) != 10 {
+		t.Fatalf(errorString, len(seen), 10)
+	}
+}
+
+func TestSampleAfterRemovals(t *testing.T) {
+	cache := seeded(1)
+	for i := 0; i < 20; i += 2 {
+		cache.Delete(i)
+	}
+	cache.ClearExpired()
+	want := []int{1, 3, 5, 7, 9}
+	if got := sampledKeys(cache.Sample(10)); !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+	cache.Clear()
+	cache.Set(1, 1)
+	if k, ok := cache.RandomKey(); !ok || k != 1 {
+		t.Fatalf(errorString, k, 1)
+	}
+}
@@ -0,0 +1,175 @@
+package cubby
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// shardReplicas is the number of virtual nodes each shard places on the hash
+// ring of a ShardedCache.
+const shardReplicas = 64
+
+// ShardedCache spreads items across several Caches, each with its own lock,
+// to reduce contention between goroutines using different keys.
+//
+// Keys are assigned to shards by consistent hashing with virtual nodes, so
+// that if the number of shards changes, only about 1/n of the keys move to a
+// different shard, rather than nearly all of them as with modulo hashing.
+type ShardedCache[K comparable, V any] struct {
+	shards []*Cache[K, V]
+	ring   []ringPoint // sorted by hash
+	hash   func(K) uint64
+}
+
+// ringPoint is a virtual node of a shard on the hash ring.
+type ringPoint struct {
+	hash  uint64
+	shard int
+}
+
+// ShardCount returns the number of shards in the cache.
+func (sc *ShardedCache[K, V]) ShardCount() int {
+	return len(sc.shards)
+}
+
+// ShardFor returns the index of the shard that key is placed in: the shard of
+// the first virtual node on the ring at or after the key's hash.
+func (sc *ShardedCache[K, V]) ShardFor(key K) int {
+	h := sc.hash(key)
+	i := sort.Search(len(sc.ring), func(i int) bool {
+		return sc.ring[i].hash >= h
+	})
+	if i == len(sc.ring) {
+		i = 0
+	}
+	return sc.ring[i].shard
+}
+
+// shard returns the shard that key is placed in.
+func (sc *ShardedCache[K, V]) shard(key K) *Cache[K, V] {
+	return sc.shards[sc.ShardFor(key)]
+}
+
+// SetItem adds or updates the item mapped to key in the cache.
+func (sc *ShardedCache[K, V]) SetItem(key K, item Item[V]) {
+	sc.shard(key).SetItem(key, item)
+}
+
+// Set adds or updates the item value mapped to key in the cache.
+func (sc *ShardedCache[K, V]) Set(key K, value V) {
+	sc.shard(key).Set(key, value)
+}
+
+// SetToExpire adds or updates the item value with an expiration date equal to
+// time now + lifetime mapped to key in the cache.
+func (sc *ShardedCache[K, V]) SetToExpire(key K, value V, lifetime time.Duration) {
+	sc.shard(key).SetToExpire(key, value, lifetime)
+}
+
+// GetItem retrieves the item mapped to key from the cache.
+func (sc *ShardedCache[K, V]) GetItem(key K) (Item[V], bool) {
+	return sc.shard(key).GetItem(key)
+}
+
+// Get retrieves the item value mapped to key from the cache.
+func (sc *ShardedCache[K, V]) Get(key K) (V, bool) {
+	return sc.shard(key).Get(key)
+}
+
+// Delete removes the item mapped to key from the cache.
+func (sc *ShardedCache[K, V]) Delete(key K) {
+	sc.shard(key).Delete(key)
+}
+
+// Clear removes all items from the cache, one shard at a time.
+func (sc *ShardedCache[K, V]) Clear() {
+	for _, s := range sc.shards {
+		s.Clear()
+	}
+}
+
+// ClearExpired removes all expired items from the cache, one shard at a time.
+func (sc *ShardedCache[K, V]) ClearExpired() {
+	for _, s := range sc.shards {
+		s.ClearExpired()
+	}
+}
+
+// Items returns a copy of the items in the cache. Each shard is copied under
+// its own lock, so the copy is not a snapshot of the whole cache at one time.
+func (sc *ShardedCache[K, V]) Items() map[K]Item[V] {
+	items := make(map[K]Item[V])
+	for _, s := range sc.shards {
+		for k, item := range s.Items() {
+			items[k] = item
+		}
+	}
+	return items
+}
+
+// Len returns the number of items in the cache.
+func (sc *ShardedCache[K, V]) Len() int {
+	n := 0
+	for _, s := range sc.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// NewShardedCache creates a ShardedCache of n shards, each a Cache with K type
+// keys and V type values configured by opts. Options apply to each shard
+// separately, so WithCapacity bounds each shard rather than the whole cache.
+// hash maps keys to ring positions; if it is nil, keys are hashed by their
+// formatted value. It panics if n is not positive.
+func NewShardedCache[K comparable, V any](n int, hash func(K) uint64, opts ...Option[K, V]) *ShardedCache[K, V] {
+	if n <= 0 {
+		panic(fmt.Sprintf("cubby: non-positive shard count %d for NewShardedCache", n))
+	}
+	if hash == nil {
+		hash = hashKey[K]
+	}
+	sc := &ShardedCache[K, V]{
+		shards: make([]*Cache[K, V], n),
+		ring:   make([]ringPoint, 0, n*shardReplicas),
+		hash:   hash,
+	}
+	for i := range sc.shards {
+		sc.shards[i] = NewCache(opts...)
+		for j := 0; j < shardReplicas; j++ {
+			sc.ring = append(sc.ring, ringPoint{
+				hash:  hashString(strconv.Itoa(i) + "-" + strconv.Itoa(j)),
+				shard: i,
+			})
+		}
+	}
+	sort.Slice(sc.ring, func(i, j int) bool {
+		return sc.ring[i].hash < sc.ring[j].hash
+	})
+	return sc
+}
+
+// hashKey hashes key by its formatted value. Strings are hashed directly.
+func hashKey[K comparable](key K) uint64 {
+	if s, ok := any(key).(string); ok {
+		return hashString(s)
+	}
+	return hashString(fmt.Sprintf("%#v", key))
+}
+
+// hashString returns the 64-bit FNV-1a hash of s, with its bits mixed so that
+// similar strings land far apart on the ring.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s)) // never returns an error
+	x := h.Sum64()
+	// splitmix64 finalizer
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,70 @@
+package cubby
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestShardedCache(t *testing.T) {
+	cache := NewShardedCache[int, int](4, nil)
+	if cache.ShardCount() != 4 {
+		t.Fatalf(errorString, cache.ShardCount(), 4)
+	}
+	for i := 0; i < 1000; i++ {
+		cache.Set(i, i)
+	}
+	if cache.Len() != 1000 {
+		t.Fatalf(errorString, cache.Len(), 1000)
+	}
+	for i, s := range cache.shards {
+		// Each shard should hold a reasonable share of the keys.
+		if n := s.Len(); n < 100 {
+			t.Fatalf("Got %v keys in shard %v but wanted at least 100", n, i)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		if v, ok := cache.shards[cache.ShardFor(i)].Get(i); !ok || v != i {
+			t.Fatalf(errorString, v, i)
+		}
+	}
+}
+
+func TestShardedCacheStablePlacement(t *testing.T) {
+	before := NewShardedCache[string, int](4, nil)
+	after := NewShardedCache[string, int](5, nil)
+	moved := 0
+	for i := 0; i < 1000; i++ {
+		k := "key" + strconv.Itoa(i)
+		if before.ShardFor(k) != after.ShardFor(k) {
+			moved++
+		}
+	}
+	// About 1/5 of keys should move; modulo hashing would move about 4/5.
+	if moved > 400 {
+		t.Fatalf("Got %v of 1000 keys moved but wanted at most 400", moved)
+	}
+}
+
+func TestShardedCacheCustomHash(t *testing.T) {
+	calls := 0
+	cache := NewShardedCache(2, func(k string) uint64 {
+		calls++
+		return hashString(k)
+	}, WithCapacity[string, int](1))
+	cache.Set("x", 1)
+	cache.Get("x")
+	if calls != 2 {
+		t.Fatalf(errorString, calls, 2)
+	}
+}
+
+func TestShardedCacheNonPositiveCount(t *testing.T) {
+	defer func() {
+		msg, ok := recover().(string)
+		if !ok || !strings.Contains(msg, "non-positive shard count") {
+			t.Fatalf(errorString, msg, "non-positive shard count panic")
+		}
+	}()
+	NewShardedCache[string, int](0, nil)
+}
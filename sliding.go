@@ -0,0 +1,38 @@
+package cubby
+
+import "time"
+
+// readSliding is read for a cache created WithSlidingTTL. It takes the write
+// lock to extend the expiration date of the item mapped to key, if it is not
+// expired and the cache is not closed, before retrieving it.
+func (c *Cache[K, V]) readSliding(key K) (Item[V], bool) {
+	c.lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return Item[V]{}, false
+	}
+	now := c.clock.Now()
+	if !e.item.ExpiredAt.IsZero() && !e.item.expiredAt(now) && !c.closed {
+		e.item.ExpiredAt = c.slide(e.item, now)
+	}
+	e.accessed.Store(now.UnixNano())
+	c.touch(e)
+	return e.item, true
+}
+
+// slide returns the expiration date of item read at now: idle after now, but
+// no later than the item's maximum lifetime allows and no earlier than its
+// current expiration date.
+func (c *Cache[K, V]) slide(item Item[V], now time.Time) time.Time {
+	deadline := now.Add(c.slidingTTL)
+	if c.maxLifetime > 0 {
+		if ceiling := item.CreatedAt.Add(c.maxLifetime); deadline.After(ceiling) {
+			deadline = ceiling
+		}
+	}
+	if deadline.Before(item.ExpiredAt) {
+		return item.ExpiredAt
+	}
+	return deadline
+}
@@ -0,0 +1,77 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestWithSlidingTTL(t *testing.T) {
+	cases := map[string]struct {
+		maxLifetime time.Duration
+		reads       []time.Duration // clock advances, each followed by a Get
+		wantLive    bool
+	}{
+		"read within idle": {
+			reads:    []time.Duration{8 * time.Minute, 8 * time.Minute, 8 * time.Minute},
+			wantLive: true,
+		},
+		"idle too long": {
+			reads:    []time.Duration{8 * time.Minute, 11 * time.Minute},
+			wantLive: false,
+		},
+		"read past max lifetime": {
+			maxLifetime: 20 * time.Minute,
+			reads:       []time.Duration{8 * time.Minute, 8 * time.Minute, 8 * time.Minute},
+			wantLive:    false,
+		},
+		"read within max lifetime": {
+			maxLifetime: 30 * time.Minute,
+			reads:       []time.Duration{8 * time.Minute, 8 * time.Minute, 8 * time.Minute},
+			wantLive:    true,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			clock := cubbytest.NewFakeClock(now)
+			cache := NewCache(
+				WithClock[string, int](clock),
+				WithSlidingTTL[string, int](10*time.Minute, c.maxLifetime),
+				WithLazyExpiration[string, int](),
+			)
+			cache.Set("x", 1)
+			ok := true
+			for _, d := range c.reads {
+				clock.Advance(d)
+				_, ok = cache.Get("x")
+			}
+			if ok != c.wantLive {
+				t.Fatalf(errorString, ok, c.wantLive)
+			}
+		})
+	}
+}
+
+func TestWithSlidingTTLDeadline(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithClock[string, int](clock),
+		WithSlidingTTL[string, int](10*time.Minute, 15*time.Minute),
+	)
+	cache.Set("x", 1)
+	cache.SetToExpire("long", 2, time.Hour)
+	cache.SetItem("forever", Item[int]{Value: 3, CreatedAt: now})
+	clock.Advance(8 * time.Minute)
+	cases := map[string]time.Time{
+		"x":       now.Add(15 * time.Minute), // capped by the max lifetime
+		"long":    now.Add(time.Hour),        // not shortened
+		"forever": {},
+	}
+	for key, want := range cases {
+		item, _ := cache.GetItem(key)
+		if !item.ExpiredAt.Equal(want) {
+			t.Fatalf("%s:"+errorString, key, item.ExpiredAt, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package cubby
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// loadSamples is the number of most recent loader durations kept to compute
+// percentiles.
+const loadSamples = 1024
+
+// Stats holds statistics about a Cache. Fields for features a cache was not
+// created with are zero.
+type Stats struct {
+	// Loads is the number of loader calls made by GetOrCompute, if the cache
+	// was created WithLoadTiming.
+	Loads int64
+	// LoadP50, LoadP95, and LoadP99 are percentiles of the durations of the
+	// most recent loader calls, if the cache was created WithLoadTiming.
+	LoadP50, LoadP95, LoadP99 time.Duration
+
+	// LoaderWait is the total time GetOrCompute calls spent waiting for a
+	// free loader slot, if the cache was created WithMaxLoaders. If it grows
+	// quickly, the limit is a bottleneck and should be raised.
+	LoaderWait time.Duration
+	// InflightWait is the total time GetOrCompute calls spent waiting on
+	// another goroutine's load of the same key.
+	InflightWait time.Duration
+
+	// TTLScale is the factor by which the lifetimes of items set now are
+	// scaled, if the cache was created WithSoftLimit. It is 1 until the
+	// cache grows past its soft limit.
+	TTLScale float64
+
+	// StaleReads is the number of reads of recently expired items, if the
+	// cache was created WithStaleReadTracking.
+	StaleReads int64
+
+	// LockWaits and RLockWaits are the number of times taking the cache's
+	// write and read lock had to wait for another holder, if the cache was
+	// created WithContentionStats. Frequent waits suggest a ShardedCache.
+	LockWaits, RLockWaits int64
+
+	// Rejected is the number of new keys refused because the cache was full,
+	// if the cache was created WithRejectWhenFull.
+	Rejected int64
+}
+
+// Stats returns statistics about the cache.
+func (c *Cache[K, V]) Stats() Stats {
+	s := Stats{
+		StaleReads: c.staleReads.Load(),
+		LockWaits:  c.lockWaits.Load(),
+		RLockWaits: c.rlockWaits.Load(),
+		Rejected:   c.rejected.Load(),
+
+		LoaderWait:   time.Duration(c.loaderWait.Load()),
+		InflightWait: time.Duration(c.inflightWait.Load()),
+	}
+	if c.loadTimes != nil {
+		s.Loads, s.LoadP50, s.LoadP95, s.LoadP99 = c.loadTimes.percentiles()
+	}
+	c.rlock()
+	defer c.mu.RUnlock()
+	if c.softLimit > 0 {
+		s.TTLScale = c.ttlScale()
+	}
+	return s
+}
+
+// lock takes the write lock, counting whether it had to wait if the cache
+// was created WithContentionStats.
+func (c *Cache[K, V]) lock() {
+	if !c.contention {
+		c.mu.Lock()
+	} else if !c.mu.TryLock() {
+		c.lockWaits.Add(1)
+		c.mu.Lock()
+	}
+}
+
+// rlock takes the read lock, counting whether it had to wait if the cache was
+// created WithContentionStats.
+func (c *Cache[K, V]) rlock() {
+	if !c.contention {
+		c.mu.RLock()
+	} else if !c.mu.TryRLock() {
+		c.rlockWaits.Add(1)
+		c.mu.RLock()
+	}
+}
+
+// loadTimes records the durations of loader calls.
+type loadTimes struct {
+	mu      sync.Mutex
+	count   int64
+	samples []time.Duration // ring buffer of the most recent durations
+}
+
+// record adds a loader call of duration d.
+func (lt *loadTimes) record(d time.Duration) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if len(lt.samples) < loadSamples {
+		lt.samples = append(lt.samples, d)
+	} else {
+		lt.samples[lt.count%loadSamples] = d
+	}
+	lt.count++
+}
+
+// percentiles returns the number of recorded calls and the 50th, 95th, and
+// 99th percentiles of the recent durations.
+func (lt *loadTimes) percentiles() (count int64, p50, p95, p99 time.Duration) {
+	lt.mu.Lock()
+	count = lt.count
+	sorted := slices.Clone(lt.samples)
+	lt.mu.Unlock()
+	if len(sorted) == 0 {
+		return count, 0, 0, 0
+	}
+	slices.Sort(sorted)
+	at := func(p int) time.Duration {
+		return sorted[(len(sorted)-1)*p/100]
+	}
+	return count, at(50), at(95), at(99)
+}
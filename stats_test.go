@@ -0,0 +1,169 @@
+package cubby
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestStatsWithoutLoadTiming(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.GetOrCompute("x", func() (int, error) { return 1, nil })
+	if s := cache.Stats(); s != (Stats{}) {
+		t.Fatalf(errorString, s, Stats{})
+	}
+}
+
+func TestStatsLoadTiming(t *testing.T) {
+	cache := NewCache(WithLoadTiming[string, int]())
+	for i := 0; i < 3; i++ {
+		cache.GetOrCompute(strconv.Itoa(i), func() (int, error) {
+			time.Sleep(1 * time.Millisecond)
+			return i, nil
+		})
+	}
+	cache.GetOrCompute("0", func() (int, error) { return 0, nil }) // hit
+	s := cache.Stats()
+	if s.Loads != 3 {
+		t.Fatalf(errorString, s.Loads, 3)
+	}
+	if s.LoadP50 < 1*time.Millisecond || s.LoadP99 < s.LoadP50 {
+		t.Fatalf("Got implausible percentiles %v", s)
+	}
+}
+
+func TestStatsLoadWaits(t *testing.T) {
+	cases := map[string]struct {
+		keys         []string
+		wantLoader   bool
+		wantInflight bool
+	}{
+		"same key":      {keys: []string{"x", "x"}, wantInflight: true},
+		"different key": {keys: []string{"x", "y"}, wantLoader: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(WithMaxLoaders[string, int](1))
+			started := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cache.GetOrCompute(c.keys[0], func() (int, error) {
+					close(started)
+					time.Sleep(10 * time.Millisecond)
+					return 1, nil
+				})
+			}()
+			<-started
+			cache.GetOrCompute(c.keys[1], func() (int, error) { return 2, nil })
+			wg.Wait()
+			s := cache.Stats()
+			if got := s.LoaderWait > 0; got != c.wantLoader {
+				t.Fatalf("LoaderWait %v:"+errorString, s.LoaderWait, got, c.wantLoader)
+			}
+			if got := s.InflightWait > 0; got != c.wantInflight {
+				t.Fatalf("InflightWait %v:"+errorString, s.InflightWait, got, c.wantInflight)
+			}
+		})
+	}
+}
+
+func TestStatsStaleReads(t *testing.T) {
+	cases := map[string]struct {
+		opts []Option[string, int]
+		want int64
+	}{
+		"untracked": {},
+		"within grace": {
+			opts: []Option[string, int]{WithStaleReadTracking[string, int](1 * time.Minute)},
+			want: 2,
+		},
+		"wider grace": {
+			opts: []Option[string, int]{WithStaleReadTracking[string, int](2 * time.Hour)},
+			want: 4,
+		},
+		"lazy expiration": {
+			opts: []Option[string, int]{
+				WithStaleReadTracking[string, int](1 * time.Minute),
+				WithLazyExpiration[string, int](),
+			},
+			want: 2, // the first read of each removes it
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			clock := cubbytest.NewFakeClock(now)
+			cache := NewCache(append(c.opts, WithClock[string, int](clock))...)
+			cache.SetItem("live", Item[int]{Value: 1, CreatedAt: now, ExpiredAt: future})
+			cache.SetItem("recent", Item[int]{Value: 2, CreatedAt: past, ExpiredAt: now.Add(-30 * time.Second)})
+			cache.SetItem("old", Item[int]{Value: 3, CreatedAt: past, ExpiredAt: past})
+			for i := 0; i < 2; i++ {
+				cache.Get("live")
+				cache.Get("recent")
+				cache.Get("old")
+				cache.Get("missing")
+			}
+			if got := cache.Stats().StaleReads; got != c.want {
+				t.Fatalf(errorString, got, c.want)
+			}
+		})
+	}
+}
+
+func TestLoadTimesPercentiles(t *testing.T) {
+	var lt loadTimes
+	for i := 1; i <= 2*loadSamples; i++ {
+		lt.record(time.Duration(i))
+	}
+	count, p50, p95, p99 := lt.percentiles()
+	if count != 2*loadSamples {
+		t.Fatalf(errorString, count, 2*loadSamples)
+	}
+	// Only the most recent loadSamples durations are kept.
+	want := []time.Duration{
+		loadSamples + 1 + (loadSamples-1)*50/100,
+		loadSamples + 1 + (loadSamples-1)*95/100,
+		loadSamples + 1 + (loadSamples-1)*99/100,
+	}
+	for i, got := range []time.Duration{p50, p95, p99} {
+		if got != want[i] {
+			t.Fatalf(errorString, got, want[i])
+		}
+	}
+}
+
+func TestStatsContention(t *testing.T) {
+	cases := map[string]struct {
+		opts  []Option[string, int]
+		waits bool
+	}{
+		"untracked": {},
+		"tracked":   {opts: []Option[string, int]{WithContentionStats[string, int]()}, waits: true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache(c.opts...)
+			cache.Set("x", 1)
+			if s := cache.Stats(); s.LockWaits != 0 || s.RLockWaits != 0 {
+				t.Fatalf(errorString, s, Stats{})
+			}
+			// Hold the write lock so that one write and one read must wait.
+			var wg sync.WaitGroup
+			wg.Add(2)
+			cache.Transact(func(*Tx[string, int]) {
+				go func() { defer wg.Done(); cache.Set("y", 2) }()
+				go func() { defer wg.Done(); cache.Get("x") }()
+				time.Sleep(10 * time.Millisecond)
+			})
+			wg.Wait()
+			s := cache.Stats()
+			if got := s.LockWaits == 1 && s.RLockWaits == 1; got != c.waits {
+				t.Fatalf(errorString, s, c.waits)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package cubby
+
+import "time"
+
+// Store is the set of methods every cache type in the package implements, so
+// code can depend on a Store and be given any of them.
+type Store[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Set(key K, value V)
+	SetToExpire(key K, value V, lifetime time.Duration)
+	Delete(key K)
+	Clear()
+	Len() int
+	Items() map[K]Item[V]
+}
+
+var (
+	_ Store[string, int] = (*Cache[string, int])(nil)
+	_ Store[string, int] = (*TickingCache[string, int])(nil)
+	_ Store[string, int] = (*ShardedCache[string, int])(nil)
+)
@@ -0,0 +1,37 @@
+package cubby
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	ticking := NewTickingCache[string, int](1 * time.Minute)
+	defer ticking.Stop()
+	cases := map[string]Store[string, int]{
+		"cache":         NewCache[string, int](),
+		"bounded cache": NewCache(WithCapacity[string, int](2)),
+		"ticking cache": ticking,
+		"sharded cache": NewShardedCache[string, int](4, nil),
+	}
+	for name, s := range cases {
+		t.Run(name, func(t *testing.T) {
+			s.Set("x", 1)
+			s.SetToExpire("y", 2, 1*time.Hour)
+			if v, ok := s.Get("x"); !ok || v != 1 {
+				t.Fatalf(errorString, v, 1)
+			}
+			if len(s.Items()) != s.Len() {
+				t.Fatalf(errorString, len(s.Items()), s.Len())
+			}
+			s.Delete("x")
+			if s.Len() != 1 {
+				t.Fatalf(errorString, s.Len(), 1)
+			}
+			s.Clear()
+			if s.Len() != 0 {
+				t.Fatalf(errorString, s.Len(), 0)
+			}
+		})
+	}
+}
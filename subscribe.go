@@ -0,0 +1,182 @@
+package cubby
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManySubscribers is returned by Subscribe when the cache already has
+// the maximum number of subscribers set by WithMaxSubscribers.
+var ErrTooManySubscribers = errors.New("cubby: too many subscribers")
+
+// Event describes an item removed from a Cache, as delivered to subscribers.
+type Event[K comparable, V any] struct {
+	Key    K
+	Item   Item[V]
+	Reason EvictReason
+}
+
+// OverflowPolicy decides what a Subscription does with an event when its
+// buffer is full.
+type OverflowPolicy int
+
+const (
+	// DropNewest discards the event that does not fit.
+	DropNewest OverflowPolicy = iota
+	// DropOldest discards the oldest buffered event to make room.
+	DropOldest
+	// Block waits for room, up to the subscription's timeout, and then
+	// discards the event. Removals from the cache wait with it.
+	Block
+)
+
+// SubscribeConfig configures a Subscription.
+type SubscribeConfig struct {
+	// Buffer is the number of events buffered for the subscriber. If it is
+	// not positive, 64 are buffered.
+	Buffer int
+	// Policy decides what happens to events when the buffer is full.
+	Policy OverflowPolicy
+	// Timeout bounds how long the Block policy waits for room. If it is not
+	// positive, Block waits until there is room or the subscription closes.
+	Timeout time.Duration
+}
+
+// Subscription delivers events for the items removed from a cache.
+type Subscription[K comparable, V any] struct {
+	// C receives the events. It is closed by Close.
+	C <-chan Event[K, V]
+
+	c       *Cache[K, V]
+	ch      chan Event[K, V]
+	cfg     SubscribeConfig
+	mu      sync.Mutex // serializes sends and closing ch
+	closed  bool
+	done    chan struct{} // closed by Close to release blocked sends
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// subscribers tracks the subscriptions of a cache.
+type subscribers[K comparable, V any] struct {
+	mu   sync.Mutex
+	subs map[*Subscription[K, V]]struct{}
+	n    atomic.Int32 // len(subs), readable without mu
+	max  int
+}
+
+// Subscribe returns a Subscription that receives an event for every item
+// removed from the cache, for any reason, as the OnEvict callback would. It
+// returns ErrTooManySubscribers if the cache was created WithMaxSubscribers
+// and already has that many. Close the subscription when done with it.
+func (c *Cache[K, V]) Subscribe(cfg SubscribeConfig) (*Subscription[K, V], error) {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = expireBuffer
+	}
+	ch := make(chan Event[K, V], cfg.Buffer)
+	s := &Subscription[K, V]{C: ch, c: c, ch: ch, cfg: cfg, done: make(chan struct{})}
+	c.subs.mu.Lock()
+	defer c.subs.mu.Unlock()
+	if c.subs.max > 0 && len(c.subs.subs) >= c.subs.max {
+		return nil, ErrTooManySubscribers
+	}
+	if c.subs.subs == nil {
+		c.subs.subs = make(map[*Subscription[K, V]]struct{})
+	}
+	c.subs.subs[s] = struct{}{}
+	c.subs.n.Add(1)
+	return s, nil
+}
+
+// Close stops the subscription and closes its channel. It is safe to call
+// more than once.
+func (s *Subscription[K, V]) Close() {
+	s.once.Do(func() {
+		s.c.subs.mu.Lock()
+		delete(s.c.subs.subs, s)
+		s.c.subs.n.Add(-1)
+		s.c.subs.mu.Unlock()
+		close(s.done)
+		s.mu.Lock()
+		s.closed = true
+		close(s.ch)
+		s.mu.Unlock()
+	})
+}
+
+// Dropped returns the number of events discarded because the subscription's
+// buffer was full.
+func (s *Subscription[K, V]) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// send delivers ev according to the subscription's overflow policy.
+func (s *Subscription[K, V]) send(ev Event[K, V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- ev:
+		return
+	default:
+	}
+	switch s.cfg.Policy {
+	case DropOldest:
+		for {
+			select {
+			case s.ch <- ev:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	case Block:
+		var timeout <-chan time.Time
+		if s.cfg.Timeout > 0 {
+			timer := time.NewTimer(s.cfg.Timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case s.ch <- ev:
+		case <-timeout:
+			s.dropped.Add(1)
+		case <-s.done:
+		}
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// publish sends evicted to every subscription. It must be called without
+// holding the cache's lock.
+func (c *Cache[K, V]) publish(evicted []eviction[K, V]) {
+	if c.subs.n.Load() == 0 || len(evicted) == 0 {
+		return
+	}
+	c.subs.mu.Lock()
+	subs := make([]*Subscription[K, V], 0, len(c.subs.subs))
+	for s := range c.subs.subs {
+		subs = append(subs, s)
+	}
+	c.subs.mu.Unlock()
+	for _, s := range subs {
+		for _, ev := range evicted {
+			s.send(Event[K, V]{ev.key, ev.item, ev.reason})
+		}
+	}
+}
+
+// observed reports whether evictions have anyone to be reported to: the
+// OnEvict callback, a subscriber, or a logger.
+func (c *Cache[K, V]) observed() bool {
+	return c.onEvict != nil || c.subs.n.Load() > 0 || c.logger != nil
+}
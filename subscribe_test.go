@@ -0,0 +1,122 @@
+package cubby
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	cache := NewCache(WithCapacity[string, int](2))
+	sub, err := cache.Subscribe(SubscribeConfig{})
+	if err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	defer sub.Close()
+	cache.Set("x", 1)
+	cache.Set("y", 2)
+	cache.Set("z", 3) // evicts x
+	cache.Delete("y")
+	cache.SetItem("w", Item[int]{Value: 4, CreatedAt: past, ExpiredAt: past})
+	cache.ClearExpired()
+	cache.Clear()
+	want := []Event[string, int]{
+		{Key: "x", Reason: EvictCapacity},
+		{Key: "y", Reason: EvictDeleted},
+		{Key: "w", Reason: EvictExpired},
+		{Key: "z", Reason: EvictCleared},
+	}
+	for _, w := range want {
+		got := <-sub.C
+		if got.Key != w.Key || got.Reason != w.Reason {
+			t.Fatalf(errorString, got, w)
+		}
+	}
+	select {
+	case got := <-sub.C:
+		t.Fatalf(errorString, got, "no more events")
+	default:
+	}
+}
+
+func TestSubscriptionOverflow(t *testing.T) {
+	cases := map[string]struct {
+		cfg     SubscribeConfig
+		want    []string
+		dropped int64
+	}{
+		"drop newest": {cfg: SubscribeConfig{Buffer: 2}, want: []string{"0", "1"}, dropped: 3},
+		"drop oldest": {cfg: SubscribeConfig{Buffer: 2, Policy: DropOldest}, want: []string{"3", "4"}, dropped: 3},
+		"block with timeout": {
+			cfg:  SubscribeConfig{Buffer: 2, Policy: Block, Timeout: 1 * time.Millisecond},
+			want: []string{"0", "1"}, dropped: 3,
+		},
+		"roomy buffer": {cfg: SubscribeConfig{Buffer: 10}, want: []string{"0", "1", "2", "3", "4"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			cache := NewCache[string, int]()
+			sub, _ := cache.Subscribe(c.cfg)
+			for i := 0; i < 5; i++ {
+				cache.Set(strconv.Itoa(i), i)
+				cache.Delete(strconv.Itoa(i))
+			}
+			sub.Close()
+			var got []string
+			for ev := range sub.C {
+				got = append(got, ev.Key)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if sub.Dropped() != c.dropped {
+				t.Fatalf(errorString, sub.Dropped(), c.dropped)
+			}
+		})
+	}
+}
+
+func TestSubscriptionBlock(t *testing.T) {
+	cache := NewCache[string, int]()
+	sub, _ := cache.Subscribe(SubscribeConfig{Buffer: 1, Policy: Block})
+	cache.Set("x", 1)
+	cache.Delete("x")
+	done := make(chan struct{})
+	go func() {
+		cache.Set("y", 2)
+		cache.Delete("y") // blocks until x is received
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("Wanted Delete to block on a full subscription but it did not")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if ev := <-sub.C; ev.Key != "x" {
+		t.Fatalf(errorString, ev.Key, "x")
+	}
+	<-done
+	if ev := <-sub.C; ev.Key != "y" {
+		t.Fatalf(errorString, ev.Key, "y")
+	}
+	sub.Close()
+}
+
+func TestMaxSubscribers(t *testing.T) {
+	cache := NewCache(WithMaxSubscribers[string, int](2))
+	a, _ := cache.Subscribe(SubscribeConfig{})
+	b, _ := cache.Subscribe(SubscribeConfig{})
+	if _, err := cache.Subscribe(SubscribeConfig{}); !errors.Is(err, ErrTooManySubscribers) {
+		t.Fatalf(errorString, err, ErrTooManySubscribers)
+	}
+	a.Close()
+	a.Close() // closing twice is harmless
+	c, err := cache.Subscribe(SubscribeConfig{})
+	if err != nil {
+		t.Fatalf(errorString, err, nil)
+	}
+	b.Close()
+	c.Close()
+}
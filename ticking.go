@@ -0,0 +1,203 @@
+package cubby
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// TickingCache extends Cache with functionality to process a job, its Job
+// field, at every interval. A common application is to clear expired entries
+// at every tick.
+type TickingCache[K comparable, V any] struct {
+	*Cache[K, V]
+	*ticking
+}
+
+// ticking is the state of a TickingCache's ticking. Its go routines refer to
+// it rather than to the TickingCache, so that a forgotten TickingCache can
+// become unreachable while ticking and be stopped by its finalizer.
+type ticking struct {
+	// Job is called at every tick. Assigning it directly races with a
+	// running ticker; use SetJob once ticking has started.
+	Job func()
+
+	runMu    sync.Mutex    // guards Job while ticking, done, and interval
+	done     chan struct{} // closed to stop ticking, nil if not running
+	interval time.Duration // of the last Start, used by Resume
+
+	jobsMu sync.Mutex
+	jobs   map[int]func() // cancel functions of jobs added with AddJob
+	nextID int
+}
+
+// Start creates a new ticker and calls Job at every tick denoted by duration
+// until Stop is called. It blocks, so it is usually run in a new go routine.
+// Any ticking already running is stopped first. It panics if d is not
+// positive, leaving any running ticking as is.
+func (tc *TickingCache[k, V]) Start(d time.Duration) {
+	checkInterval("Start", d)
+	ticker, done := tc.begin(d)
+	tc.run(ticker, done)
+}
+
+// Resume starts ticking again in a new go routine at the interval of the last
+// Start after Stop was called. It does nothing if ticking is running. Jobs
+// added with AddJob are not resumed.
+func (tc *TickingCache[K, V]) Resume() {
+	tc.runMu.Lock()
+	running, d := tc.done != nil, tc.interval
+	tc.runMu.Unlock()
+	if running || d <= 0 {
+		return
+	}
+	ticker, done := tc.begin(d)
+	go tc.run(ticker, done)
+}
+
+// IsRunning reports whether the cache is ticking, i.e. it was started and not
+// since stopped.
+func (tc *TickingCache[K, V]) IsRunning() bool {
+	tc.runMu.Lock()
+	defer tc.runMu.Unlock()
+	return tc.done != nil
+}
+
+// SetJob replaces Job, safely even while ticking is running. The next tick
+// calls job.
+func (tc *TickingCache[K, V]) SetJob(job func()) {
+	tc.runMu.Lock()
+	defer tc.runMu.Unlock()
+	tc.Job = job
+}
+
+// begin stops any running ticking and records a new run at interval d.
+func (tc *TickingCache[K, V]) begin(d time.Duration) (*time.Ticker, chan struct{}) {
+	ticker := time.NewTicker(d)
+	tc.runMu.Lock()
+	defer tc.runMu.Unlock()
+	if tc.done != nil {
+		close(tc.done)
+	}
+	tc.done, tc.interval = make(chan struct{}), d
+	return ticker, tc.done
+}
+
+// run calls Job at every tick of ticker until done is closed.
+func (t *ticking) run(ticker *time.Ticker, done chan struct{}) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.runMu.Lock()
+			job := t.Job
+			t.runMu.Unlock()
+			if job != nil {
+				job()
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// Stop immediately stops ticking to prevent Job and any jobs added with
+// AddJob from being called, ending their go routines, and stores any values
+// pending from SetDebounced.
+func (tc *TickingCache[K, V]) Stop() {
+	tc.runMu.Lock()
+	if tc.done != nil {
+		close(tc.done)
+		tc.done = nil
+	}
+	tc.runMu.Unlock()
+	tc.jobsMu.Lock()
+	jobs := tc.jobs
+	tc.jobs = nil
+	tc.jobsMu.Unlock()
+	for _, cancel := range jobs {
+		cancel()
+	}
+	tc.Flush()
+}
+
+// AddJob starts a new go routine that calls fn at every tick denoted by
+// duration, independently of Job and any other added jobs. The returned
+// cancel function stops just this job; Stop stops every job. It panics if d
+// is not positive.
+func (tc *TickingCache[K, V]) AddJob(d time.Duration, fn func()) (cancel func()) {
+	checkInterval("AddJob", d)
+	ticker := time.NewTicker(d)
+	done := make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	tc.jobsMu.Lock()
+	defer tc.jobsMu.Unlock()
+	id := tc.nextID
+	tc.nextID++
+	var once sync.Once
+	stop := func() {
+		once.Do(func() { close(done) })
+	}
+	if tc.jobs == nil {
+		tc.jobs = make(map[int]func())
+	}
+	tc.jobs[id] = stop
+	return func() {
+		tc.jobsMu.Lock()
+		delete(tc.jobs, id)
+		tc.jobsMu.Unlock()
+		stop()
+	}
+}
+
+// NewTickingCache creates a Cache with K type keys and V type values configured
+// by opts and starts a single, new go routine that calls job at every tick
+// denoted by duration. It panics if d is not positive.
+func NewTickingCache[K comparable, V any](d time.Duration, opts ...Option[K, V]) *TickingCache[K, V] {
+	checkInterval("NewTickingCache", d)
+	tc := newTickingCache(NewCache(opts...))
+	ticker, done := tc.begin(d)
+	go tc.run(ticker, done)
+	return tc
+}
+
+// NewExpiringCache creates a TickingCache whose Job is set to ClearExpired,
+// so expired items are removed at every tick denoted by duration. Job may
+// still be reassigned. It panics if d is not positive.
+func NewExpiringCache[K comparable, V any](d time.Duration, opts ...Option[K, V]) *TickingCache[K, V] {
+	checkInterval("NewExpiringCache", d)
+	tc := newTickingCache(NewCache(opts...))
+	tc.Job = tc.ClearExpired
+	ticker, done := tc.begin(d)
+	go tc.run(ticker, done)
+	return tc
+}
+
+// newTickingCache returns a TickingCache of c that is not ticking. If c was
+// created WithFinalizer, the TickingCache is stopped once it is unreachable.
+func newTickingCache[K comparable, V any](c *Cache[K, V]) *TickingCache[K, V] {
+	tc := &TickingCache[K, V]{Cache: c, ticking: &ticking{}}
+	if c.finalize {
+		runtime.SetFinalizer(tc, (*TickingCache[K, V]).Stop)
+	}
+	return tc
+}
+
+// checkInterval panics with a helpful message if d is not positive.
+func checkInterval(fn string, d time.Duration) {
+	if d <= 0 {
+		panic(fmt.Sprintf("cubby: non-positive interval %v for %s", d, fn))
+	}
+}
@@ -0,0 +1,214 @@
+package cubby
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTickingCache(t *testing.T) {
+	cache := NewTickingCache[string, int](1 * time.Minute)
+	for _, k := range keys {
+		if _, ok := cache.Get(k); ok {
+			t.Fatalf("Got value for %s but %s should not exist.", k, k)
+		}
+	}
+}
+
+func TestTickingCacheNonPositiveDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"zero":     0,
+		"negative": -1 * time.Second,
+	}
+	for name, d := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				msg, ok := r.(string)
+				if !ok || !strings.Contains(msg, "non-positive interval") {
+					t.Fatalf(errorString, r, "non-positive interval panic")
+				}
+			}()
+			NewTickingCache[string, int](d)
+		})
+	}
+}
+
+func TestTickingCacheStartNonPositiveDuration(t *testing.T) {
+	cache := NewTickingCache[string, int](1 * time.Hour)
+	defer cache.Stop()
+	func() {
+		defer func() {
+			r := recover()
+			msg, ok := r.(string)
+			if !ok || !strings.Contains(msg, "non-positive interval") {
+				t.Fatalf(errorString, r, "non-positive interval panic")
+			}
+		}()
+		cache.Start(0)
+	}()
+	if !cache.IsRunning() {
+		t.Fatalf("Got ticking stopped by a failed Start but wanted it running")
+	}
+	cache.Stop()
+	cache.Resume()
+	if !cache.IsRunning() {
+		t.Fatalf("Got ticking stopped after Resume but wanted it running")
+	}
+}
+
+func TestTickingCacheStartAndStop(t *testing.T) {
+	cache := NewTickingCache[string, int](5 * time.Millisecond)
+	cache.SetJob(func() {
+		cache.ClearExpired()
+	})
+	values := []int{1, 2, 3}
+	for i, k := range keys {
+		cache.SetToExpire(k, values[i], 1*time.Millisecond)
+	}
+	if cache.Len() != len(values) {
+		t.Fatalf("Got cache length %v but wanted %v", cache.Len(), len(values))
+	}
+	time.Sleep(10 * time.Millisecond)
+	if cache.Len() != 0 {
+		t.Fatalf("Got %v items but wanted cache to be empty", cache.Len())
+	}
+	for i, k := range keys {
+		cache.SetToExpire(k, values[i], 1*time.Millisecond)
+	}
+	if cache.Len() != len(values) {
+		t.Fatalf("Got cache length %v but wanted %v", cache.Len(), len(values))
+	}
+	cache.Stop()
+	time.Sleep(10 * time.Millisecond)
+	if cache.Len() == 0 { // ticker did not stop and items were cleared
+		t.Fatalf("Got empty cache but wanted to have %v items", cache.Len())
+	}
+}
+
+func TestTickingCacheIsRunning(t *testing.T) {
+	before := runtime.NumGoroutine()
+	cache := NewTickingCache[string, int](1 * time.Millisecond)
+	var ticks atomic.Int32
+	cache.SetJob(func() { ticks.Add(1) })
+	steps := []struct {
+		name    string
+		do      func()
+		running bool
+		ticking bool
+	}{
+		{name: "new", do: func() {}, running: true, ticking: true},
+		{name: "stop", do: cache.Stop, running: false, ticking: false},
+		{name: "stop again", do: cache.Stop, running: false, ticking: false},
+		{name: "resume", do: cache.Resume, running: true, ticking: true},
+		{name: "resume again", do: cache.Resume, running: true, ticking: true},
+		{name: "stop after resume", do: cache.Stop, running: false, ticking: false},
+		{name: "start", do: func() { go cache.Start(1 * time.Millisecond) }, running: true, ticking: true},
+		{name: "final stop", do: cache.Stop, running: false, ticking: false},
+	}
+	for _, s := range steps {
+		s.do()
+		time.Sleep(5 * time.Millisecond) // let a started loop begin or a stopped one finish
+		if got := cache.IsRunning(); got != s.running {
+			t.Fatalf("%s:"+errorString, s.name, got, s.running)
+		}
+		n := ticks.Load()
+		time.Sleep(10 * time.Millisecond)
+		if got := ticks.Load() > n; got != s.ticking {
+			t.Fatalf("%s: ticking"+errorString, s.name, got, s.ticking)
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("Got %v goroutines after Stop but wanted at most %v", after, before)
+	}
+}
+
+func TestTickingCacheFinalizer(t *testing.T) {
+	cases := map[string]func(cache *TickingCache[string, int]){
+		"AddJob": func(cache *TickingCache[string, int]) {
+			cache.AddJob(1*time.Millisecond, func() {})
+		},
+		"SweepTier": func(cache *TickingCache[string, int]) {
+			cache.SweepTier(1*time.Millisecond, "short")
+		},
+	}
+	for name, addJob := range cases {
+		t.Run(name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			var evicted atomic.Int32
+			func() {
+				cache := NewExpiringCache(1*time.Millisecond,
+					WithFinalizer[string, int](),
+					WithTier[string, int]("short", time.Hour),
+					WithOnEvict(func(_ string, _ int, reason EvictReason) {
+						if reason == EvictCleared {
+							evicted.Add(1)
+						}
+					}),
+				)
+				addJob(cache)
+				cache.Set("x", 1)
+				cache.Set("y", 2)
+			}()
+			for i := 0; i < 100 && evicted.Load() < 2; i++ {
+				runtime.GC()
+				time.Sleep(1 * time.Millisecond)
+			}
+			if got := evicted.Load(); got != 2 {
+				t.Fatalf(errorString, got, 2)
+			}
+			if after := runtime.NumGoroutine(); after > before {
+				t.Fatalf("Got %v goroutines after finalizing but wanted at most %v", after, before)
+			}
+		})
+	}
+}
+
+func TestExpiringCache(t *testing.T) {
+	cache := NewExpiringCache[string, int](5 * time.Millisecond)
+	defer cache.Stop()
+	values := []int{1, 2, 3}
+	for i, k := range keys {
+		cache.SetToExpire(k, values[i], 1*time.Millisecond)
+	}
+	cache.Set("w", 4)
+	time.Sleep(20 * time.Millisecond)
+	if cache.Len() != 1 {
+		t.Fatalf(errorString, cache.Len(), 1)
+	}
+}
+
+func TestAddJob(t *testing.T) {
+	cache := NewTickingCache[string, int](1 * time.Hour)
+	defer cache.Stop()
+	var fast, slow atomic.Int32
+	cancelFast := cache.AddJob(1*time.Millisecond, func() { fast.Add(1) })
+	cache.AddJob(1*time.Hour, func() { slow.Add(1) })
+	time.Sleep(20 * time.Millisecond)
+	cancelFast()
+	cancelFast() // cancelling twice is harmless
+	n := fast.Load()
+	if n == 0 {
+		t.Fatalf("Wanted fast job to run but it did not")
+	}
+	if slow.Load() != 0 {
+		t.Fatalf(errorString, slow.Load(), 0)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := fast.Load(); got > n+1 { // at most one call in flight at cancel
+		t.Fatalf("Got %v calls after cancel but wanted at most %v", got, n+1)
+	}
+}
+
+func TestStopCancelsJobs(t *testing.T) {
+	cache := NewTickingCache[string, int](1 * time.Hour)
+	var calls atomic.Int32
+	cache.AddJob(1*time.Millisecond, func() { calls.Add(1) })
+	cache.Stop()
+	time.Sleep(10 * time.Millisecond)
+	if n := calls.Load(); n > 1 {
+		t.Fatalf("Got %v calls after Stop but wanted at most 1", n)
+	}
+}
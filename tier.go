@@ -0,0 +1,85 @@
+package cubby
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnknownTier is returned by SetInTier for a tier the cache was not
+// created with.
+var ErrUnknownTier = errors.New("cubby: unknown tier")
+
+// SetInTier adds or updates the item value mapped to key in the named TTL
+// tier declared WithTier, so that it expires after the tier's lifetime and is
+// swept along with the rest of the tier by ClearExpiredTier. Tiers suit items
+// that fall into a few well-known lifetime classes. The key leaves the tier
+// when it is set again by other means or removed. It returns an error
+// wrapping ErrUnknownTier if the tier was not declared, and ErrClosed if the
+// cache is closed.
+func (c *Cache[K, V]) SetInTier(key K, value V, tier string) error {
+	lifetime, ok := c.tiers[tier]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownTier, tier)
+	}
+	c.lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	now := c.clock.Now()
+	evicted, _ := c.set(key, Item[V]{Value: value, CreatedAt: now, ExpiredAt: now.Add(c.scaled(lifetime))}, 1)
+	if e, ok := c.items[key]; ok {
+		e.tier = tier
+		if c.tierKeys == nil {
+			c.tierKeys = make(map[string]map[K]struct{})
+		}
+		if c.tierKeys[tier] == nil {
+			c.tierKeys[tier] = make(map[K]struct{})
+		}
+		c.tierKeys[tier][key] = struct{}{}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+	return nil
+}
+
+// ClearExpiredTier is like ClearExpired but removes only the expired items in
+// the named tier, visiting only that tier's keys. It returns how many items
+// it removed.
+func (c *Cache[K, V]) ClearExpiredTier(tier string) int {
+	c.lock()
+	now := c.clock.Now()
+	expired := c.expired
+	var evicted []eviction[K, V]
+	for key := range c.tierKeys[tier] {
+		if e := c.items[key]; e.item.expiredAt(now) {
+			c.remove(key)
+			evicted = append(evicted, eviction[K, V]{key, e.item, EvictExpired})
+		}
+	}
+	c.mu.Unlock()
+	c.swept(expired, evicted)
+	return len(evicted)
+}
+
+// SweepTier adds a job, as AddJob does, that calls ClearExpiredTier for the
+// named tier at every tick denoted by d, so that each tier can be swept at
+// its own cadence. It panics if d is not positive.
+func (tc *TickingCache[K, V]) SweepTier(d time.Duration, tier string) (cancel func()) {
+	checkInterval("SweepTier", d)
+	c := tc.Cache // not tc, which the job must not keep reachable
+	return tc.AddJob(d, func() {
+		c.ClearExpiredTier(tier)
+	})
+}
+
+// untier removes key from the tier of its entry e, if any. The caller must
+// hold the write lock.
+func (c *Cache[K, V]) untier(key K, e *entry[K, V]) {
+	if e.tier == "" {
+		return
+	}
+	delete(c.tierKeys[e.tier], key)
+	e.tier = ""
+}
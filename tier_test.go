@@ -0,0 +1,85 @@
+package cubby
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/novrin/cubby/cubbytest"
+)
+
+func TestSetInTier(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	cache := NewCache(
+		WithClock[string, int](clock),
+		WithTier[string, int]("short", 1*time.Minute),
+		WithTier[string, int]("long", 1*time.Hour),
+	)
+	cases := map[string]struct {
+		tier      string
+		expiredAt time.Time
+		err       error
+	}{
+		"short":   {tier: "short", expiredAt: now.Add(1 * time.Minute)},
+		"long":    {tier: "long", expiredAt: now.Add(1 * time.Hour)},
+		"unknown": {tier: "medium", err: ErrUnknownTier},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := cache.SetInTier(name, 1, c.tier)
+			if !errors.Is(err, c.err) {
+				t.Fatalf(errorString, err, c.err)
+			}
+			if item, _ := cache.GetItem(name); item.ExpiredAt != c.expiredAt {
+				t.Fatalf(errorString, item.ExpiredAt, c.expiredAt)
+			}
+		})
+	}
+}
+
+func TestClearExpiredTier(t *testing.T) {
+	clock := cubbytest.NewFakeClock(now)
+	var swept int
+	cache := NewCache(
+		WithClock[string, int](clock),
+		WithTier[string, int]("short", 1*time.Minute),
+		WithTier[string, int]("other", 1*time.Minute),
+		WithOnSweep[string, int](func(expired map[string]Item[int]) { swept += len(expired) }),
+	)
+	cache.SetInTier("a", 1, "short")
+	cache.SetInTier("b", 2, "short")
+	cache.SetInTier("c", 3, "other")
+	cache.SetInTier("d", 4, "short")
+	cache.SetToExpire("d", 4, 1*time.Second) // leaves the tier
+	cache.SetToExpire("e", 5, 1*time.Second)
+	cache.Delete("b")
+	clock.Advance(2 * time.Minute)
+	if n := cache.ClearExpiredTier("short"); n != 1 {
+		t.Fatalf(errorString, n, 1)
+	}
+	if swept != 1 {
+		t.Fatalf(errorString, swept, 1)
+	}
+	if got, want := sortedKeys(cache.Items()), []string{"c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf(errorString, got, want)
+	}
+	if n := cache.ClearExpiredTier("missing"); n != 0 {
+		t.Fatalf(errorString, n, 0)
+	}
+	cache.ClearExpired()
+	if cache.Len() != 0 {
+		t.Fatalf(errorString, cache.Len(), 0)
+	}
+}
+
+func TestSweepTier(t *testing.T) {
+	cache := NewTickingCache(1*time.Hour, WithTier[string, int]("short", 1*time.Millisecond))
+	defer cache.Stop()
+	cache.SetInTier("x", 1, "short")
+	cache.SweepTier(1*time.Millisecond, "short")
+	time.Sleep(20 * time.Millisecond)
+	if cache.Len() != 0 {
+		t.Fatalf(errorString, cache.Len(), 0)
+	}
+}
@@ -0,0 +1,53 @@
+package cubby
+
+// Tx reads and writes a cache whose write lock is held by Transact. A Tx is
+// only valid until the function passed to Transact returns; it must not be
+// retained or used from another goroutine.
+type Tx[K comparable, V any] struct {
+	c       *Cache[K, V]
+	evicted []eviction[K, V]
+}
+
+// Transact calls fn while holding the write lock, so that the reads and
+// writes fn makes through tx appear atomic to other goroutines. This suits
+// updates that must keep several keys consistent with one another. OnEvict
+// callbacks for items removed by fn run after fn returns and the lock is
+// released.
+//
+// fn must not call methods of the cache itself, or it will deadlock.
+func (c *Cache[K, V]) Transact(fn func(tx *Tx[K, V])) {
+	tx := &Tx[K, V]{c: c}
+	c.lock()
+	defer func() {
+		c.mu.Unlock()
+		c.notify(tx.evicted)
+	}()
+	fn(tx)
+}
+
+// GetItem retrieves the item mapped to key. Like the cache's GetItem, it
+// returns expired items until they are removed.
+func (tx *Tx[K, V]) GetItem(key K) (Item[V], bool) {
+	return tx.c.read(key)
+}
+
+// Get retrieves the item value mapped to key.
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	item, ok := tx.c.read(key)
+	return item.Value, ok
+}
+
+// Set adds or updates the item value mapped to key, like the cache's Set.
+func (tx *Tx[K, V]) Set(key K, value V) {
+	evicted, _ := tx.c.set(key, tx.c.newItem(value), 1)
+	tx.evicted = append(tx.evicted, evicted...)
+}
+
+// Delete removes the item mapped to key and reports whether it was present.
+func (tx *Tx[K, V]) Delete(key K) bool {
+	e, ok := tx.c.remove(key)
+	if ok && tx.c.observed() {
+		tx.evicted = append(tx.evicted, eviction[K, V]{key, e.item, EvictDeleted})
+	}
+	return ok
+}
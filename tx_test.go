@@ -0,0 +1,103 @@
+package cubby
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTransact(t *testing.T) {
+	cases := map[string]struct {
+		fn      func(tx *Tx[string, int])
+		want    map[string]int
+		evicted []string
+	}{
+		"move": {
+			fn: func(tx *Tx[string, int]) {
+				v, _ := tx.Get("x")
+				tx.Delete("x")
+				tx.Set("z", v)
+			},
+			want:    map[string]int{"y": 2, "z": 1},
+			evicted: []string{"x"},
+		},
+		"swap": {
+			fn: func(tx *Tx[string, int]) {
+				x, _ := tx.Get("x")
+				y, _ := tx.Get("y")
+				tx.Set("x", y)
+				tx.Set("y", x)
+			},
+			want: map[string]int{"x": 2, "y": 1},
+		},
+		"delete missing": {
+			fn: func(tx *Tx[string, int]) {
+				if tx.Delete("z") {
+					t.Fatalf(errorString, true, false)
+				}
+			},
+			want: map[string]int{"x": 1, "y": 2},
+		},
+		"evict for capacity": {
+			fn: func(tx *Tx[string, int]) {
+				tx.Set("z", 3)
+				tx.Set("w", 4)
+			},
+			want:    map[string]int{"w": 4, "z": 3, "y": 2},
+			evicted: []string{"x"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var evicted []string
+			cache := NewCache[string, int](
+				WithCapacity[string, int](3),
+				WithOnEvict(func(key string, _ int, _ EvictReason) {
+					evicted = append(evicted, key)
+				}),
+			)
+			cache.Set("x", 1)
+			cache.Set("y", 2)
+			cache.Transact(c.fn)
+			if got := cache.ToMap(); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf(errorString, got, c.want)
+			}
+			if !reflect.DeepEqual(evicted, c.evicted) {
+				t.Fatalf(errorString, evicted, c.evicted)
+			}
+		})
+	}
+}
+
+func TestTransactAtomic(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 100)
+	cache.Set("y", 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			cache.Transact(func(tx *Tx[string, int]) {
+				x, _ := tx.Get("x")
+				y, _ := tx.Get("y")
+				tx.Set("x", x-1)
+				tx.Set("y", y+1)
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			cache.Transact(func(tx *Tx[string, int]) {
+				x, _ := tx.Get("x")
+				y, _ := tx.Get("y")
+				if x+y != 100 {
+					t.Errorf(errorString, x+y, 100)
+				}
+			})
+		}()
+	}
+	wg.Wait()
+	if got, _ := cache.Get("y"); got != 100 {
+		t.Fatalf(errorString, got, 100)
+	}
+}
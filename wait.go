@@ -0,0 +1,60 @@
+package cubby
+
+import "context"
+
+// Wait retrieves the item value mapped to key from the cache. If key is not
+// in the cache, Wait blocks until it is set or ctx is done, in which case it
+// returns ctx.Err().
+func (c *Cache[K, V]) Wait(ctx context.Context, key K) (V, error) {
+	c.lock()
+	if e, ok := c.items[key]; ok {
+		c.mu.Unlock()
+		return e.item.Value, nil
+	}
+	ch := make(chan V, 1)
+	if c.waiters == nil {
+		c.waiters = make(map[K][]chan V)
+	}
+	c.waiters[key] = append(c.waiters[key], ch)
+	c.mu.Unlock()
+
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-ctx.Done():
+	}
+	c.lock()
+	chans := c.waiters[key]
+	for i, w := range chans {
+		if w == ch {
+			chans = append(chans[:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(chans) == 0 {
+		delete(c.waiters, key)
+	} else {
+		c.waiters[key] = chans
+	}
+	c.mu.Unlock()
+	select {
+	case v := <-ch: // set before the waiter was removed
+		return v, nil
+	default:
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// wake sends value to every goroutine waiting on key. The caller must hold the
+// write lock.
+func (c *Cache[K, V]) wake(key K, value V) {
+	chans, ok := c.waiters[key]
+	if !ok {
+		return
+	}
+	for _, ch := range chans {
+		ch <- value
+	}
+	delete(c.waiters, key)
+}
@@ -0,0 +1,59 @@
+package cubby
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitPresent(t *testing.T) {
+	cache := NewCache[string, int]()
+	cache.Set("x", 1)
+	got, err := cache.Wait(context.Background(), "x")
+	if err != nil || got != 1 {
+		t.Fatalf(errorString, got, 1)
+	}
+}
+
+func TestWaitUntilSet(t *testing.T) {
+	cache := NewCache[string, int]()
+	results := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			v, _ := cache.Wait(context.Background(), "x")
+			results <- v
+		}()
+	}
+	for {
+		cache.mu.RLock()
+		n := len(cache.waiters["x"])
+		cache.mu.RUnlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+	cache.Set("x", 7)
+	for i := 0; i < 2; i++ {
+		if got := <-results; got != 7 {
+			t.Fatalf(errorString, got, 7)
+		}
+	}
+	if len(cache.waiters) != 0 {
+		t.Fatalf("Got %v waiters but wanted none", len(cache.waiters))
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	cache := NewCache[string, int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err := cache.Wait(ctx, "x")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(errorString, err, context.DeadlineExceeded)
+	}
+	if len(cache.waiters) != 0 {
+		t.Fatalf("Got %v waiters but wanted none", len(cache.waiters))
+	}
+}